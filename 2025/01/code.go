@@ -1,14 +1,35 @@
 package main
 
 import (
-	"strings"
+	"aoc-in-go/harness"
 	"fmt"
-	"github.com/jpillora/puzzler/harness/aoc"
+	"iter"
+	"log"
+	"os"
 	"strconv"
+	"strings"
 )
 
+// DIAL_SIZE and DIAL_START configure the dial, 100 clicks starting at 50 by default
+var dialSize, dialStart int64 = 100, 50
+
 func main() {
-	aoc.Harness(run)
+	for name, value := range map[string]*int64{"DIAL_SIZE": &dialSize, "DIAL_START": &dialStart} {
+		if env := os.Getenv(name); env != "" {
+			n, err := strconv.ParseInt(env, 10, 64)
+			if err != nil {
+				log.Fatalf("invalid %s %q", name, env)
+			}
+			*value = n
+		}
+	}
+	if dialSize < 1 || dialStart < 0 || dialStart >= dialSize {
+		log.Fatalf("invalid dial: size %d, start %d", dialSize, dialStart)
+	}
+	harness.Harness(run,
+		harness.Setting("size", strconv.FormatInt(dialSize, 10)),
+		harness.Setting("start", strconv.FormatInt(dialStart, 10)),
+	)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,31 +39,137 @@ func main() {
 // 4. with: true (part2), and user input
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
-	delta := map[byte]int{'L': -1, 'R': 1}
-	dial := 50
-	zeroCount := 0
-	zeroCount2 := 0
-
-	for i, s := range strings.Fields(string(input)) {
-		n, _ := strconv.Atoi(s[1:])
+	rotations := parseRotations(input)
 
-		for range n {
-			if dial += delta[s[0]]; dial%100 == 0 {
-				fmt.Printf("ZERO FOUND line %d: %s\n", i, s)
-				zeroCount2++
-			}
-		}
-		if dial%100 == 0 {
-			zeroCount++
+	// TRACE=1 lists every time the dial points at 0
+	if harness.Tracing() {
+		for event := range NewDial(dialSize, dialStart).Events(rotations) {
+			harness.Trace().Debug("zero", "line", event.Line, "instruction", event.Instruction,
+				"click", event.Click, "landed", event.Landed)
 		}
 	}
 
+	dial := NewDial(dialSize, dialStart)
+	for _, rotation := range rotations {
+		dial.Rotate(rotation.Direction, rotation.Clicks)
+	}
+
 	if part2 {
-		return zeroCount2
+		return dial.ZeroHits
 	}
 
 	// solve part 1 here
-	return zeroCount
+	return dial.ZeroStops
 }
 
+// Rotation is an input line such as L68.
+type Rotation struct {
+	Line        int
+	Instruction string
+	Direction   byte
+	Clicks      int64
+}
 
+func parseRotations(input string) []Rotation {
+	var rotations []Rotation
+	for i, s := range strings.Fields(string(input)) {
+		n, _ := strconv.ParseInt(s[1:], 10, 64)
+		rotations = append(rotations, Rotation{Line: i, Instruction: s, Direction: s[0], Clicks: n})
+	}
+	return rotations
+}
+
+// Event is the dial pointing at 0 during a rotation.
+type Event struct {
+	Rotation
+	// Click is the number of clicks into the rotation, Clicks when it ends on 0
+	Click int64
+	// Landed is true when the rotation ends on 0, false when passing by
+	Landed bool
+}
+
+func (e Event) String() string {
+	action := "passed"
+	if e.Landed {
+		action = "landed on"
+	}
+	return fmt.Sprintf("line %d: %s %s 0 at click %d", e.Line, e.Instruction, action, e.Click)
+}
+
+// Dial is a circular dial of Size clicks numbered from 0.
+type Dial struct {
+	Size     int64
+	Position int64
+	// ZeroHits counts the clicks landing on 0, while passing by or not (part 2)
+	ZeroHits int64
+	// ZeroStops counts the rotations ending on 0 (part 1)
+	ZeroStops int64
+}
+
+func NewDial(size, start int64) *Dial {
+	return &Dial{Size: size, Position: start}
+}
+
+// Rotate turns the dial by n clicks to the left ('L') or the right ('R') and
+// returns the number of times it pointed at 0 on the way. Rather than
+// stepping click by click, the distance to the first 0 in that direction
+// gives the first hit, and then there is one more every full turn.
+func (d *Dial) Rotate(direction byte, n int64) int64 {
+	toZero := d.toZero(direction)
+	hits := int64(0)
+	if n >= toZero {
+		hits = 1 + (n-toZero)/d.Size
+	}
+
+	// the same position is reached turning right by step clicks
+	step := n % d.Size
+	if direction == 'L' && step > 0 {
+		step = d.Size - step
+	}
+	// Position+step could overflow for a size near MaxInt64
+	if step >= d.Size-d.Position {
+		d.Position -= d.Size - step
+	} else {
+		d.Position += step
+	}
+
+	d.ZeroHits += hits
+	if d.Position == 0 {
+		d.ZeroStops++
+	}
+	return hits
+}
+
+// toZero is the number of clicks to the next 0 going in direction.
+func (d *Dial) toZero(direction byte) int64 {
+	toZero := d.Position
+	if direction == 'R' {
+		toZero = d.Size - d.Position
+	}
+	if toZero == 0 {
+		return d.Size
+	}
+	return toZero
+}
+
+// Events applies the rotations and yields an event every time the dial
+// points at 0, so they can be filtered, counted or exported. The events are
+// generated from the hits arithmetic, not by stepping, but a huge rotation
+// can still yield a lot of them.
+func (d *Dial) Events(rotations []Rotation) iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		for _, rotation := range rotations {
+			for click := d.toZero(rotation.Direction); click <= rotation.Clicks; click += d.Size {
+				if !yield(Event{Rotation: rotation, Click: click, Landed: click == rotation.Clicks}) {
+					return
+				}
+				// the next click is past the rotation, stop before click+Size
+				// overflows for a rotation near MaxInt64
+				if click > rotation.Clicks-d.Size {
+					break
+				}
+			}
+			d.Rotate(rotation.Direction, rotation.Clicks)
+		}
+	}
+}
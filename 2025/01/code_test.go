@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// click turns a dial one click at a time, returning the clicks into the
+// rotation at which it pointed at 0.
+func click(d *Dial, direction byte, n int64) []int64 {
+	var zeros []int64
+	for i := int64(1); i <= n; i++ {
+		if direction == 'L' {
+			d.Position = (d.Position - 1 + d.Size) % d.Size
+		} else {
+			d.Position = (d.Position + 1) % d.Size
+		}
+		if d.Position == 0 {
+			zeros = append(zeros, i)
+		}
+	}
+	return zeros
+}
+
+func TestRotate(t *testing.T) {
+	for _, size := range []int64{1, 2, 5, 100} {
+		for start := range size {
+			for _, direction := range []byte{'L', 'R'} {
+				// from a start on 0, to rotations of several full turns
+				for n := range 3*size + 1 {
+					d, want := NewDial(size, start), NewDial(size, start)
+					zeros := click(want, direction, n)
+					if hits := d.Rotate(direction, n); hits != int64(len(zeros)) || d.Position != want.Position {
+						t.Fatalf("size %d, start %d: %c%d = %d hits at %d, want %d at %d",
+							size, start, direction, n, hits, d.Position, len(zeros), want.Position)
+					}
+					events := slices.Collect(NewDial(size, start).Events([]Rotation{{Direction: direction, Clicks: n}}))
+					if len(events) != len(zeros) {
+						t.Fatalf("size %d, start %d: %c%d has %d events, want %d", size, start, direction, n, len(events), len(zeros))
+					}
+					for i, event := range events {
+						if event.Click != zeros[i] || event.Landed != (zeros[i] == n) {
+							t.Errorf("size %d, start %d: %c%d event %d = %v, want click %d", size, start, direction, n, i, event, zeros[i])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRotateHuge(t *testing.T) {
+	// 50 clicks to 0, then one more 0 every 100 clicks, back on 50
+	d := NewDial(100, 50)
+	if hits := d.Rotate('L', 1000000000); hits != 10000000 || d.Position != 50 {
+		t.Errorf("L1000000000 = %d hits at %d, want 10000000 at 50", hits, d.Position)
+	}
+	if hits := d.Rotate('R', 1000000050); hits != 10000001 || d.Position != 0 || d.ZeroStops != 1 {
+		t.Errorf("R1000000050 = %d hits at %d, %d stops, want 10000001 at 0, 1 stop", hits, d.Position, d.ZeroStops)
+	}
+
+	// Position+step past MaxInt64
+	d = NewDial(math.MaxInt64, math.MaxInt64-1)
+	if hits := d.Rotate('R', math.MaxInt64-1); hits != 1 || d.Position != math.MaxInt64-2 {
+		t.Errorf("size MaxInt64: R%d = %d hits at %d", int64(math.MaxInt64-1), hits, d.Position)
+	}
+}
+
+func TestEventsHuge(t *testing.T) {
+	// the zeros are 1, 1+2^62 then 1+2^63, which is past the rotation and
+	// would overflow
+	d := NewDial(1<<62, 1)
+	var clicks []int64
+	for event := range d.Events([]Rotation{{Direction: 'L', Clicks: math.MaxInt64}}) {
+		clicks = append(clicks, event.Click)
+		if len(clicks) > 2 {
+			break
+		}
+	}
+	if want := []int64{1, 1 + 1<<62}; !slices.Equal(clicks, want) {
+		t.Errorf("events at clicks %v, want %v", clicks, want)
+	}
+	if d.ZeroHits != 2 {
+		t.Errorf("%d hits, want 2", d.ZeroHits)
+	}
+}
@@ -1,13 +1,15 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/digitpattern"
+	"aoc-in-go/harness"
+	"math/big"
 	"strings"
 	"strconv"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,7 +20,7 @@ func main() {
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
 
-	result := 0
+	result := new(big.Int)
 
 	for _, s := range strings.Fields(string(input)) {
 		// Split the string by commas to get individual ranges
@@ -32,59 +34,19 @@ func run(part2 bool, input string) any {
 				continue
 			}
 
-			firstId, _ := strconv.Atoi(parts[0])
-			lastId, _ := strconv.Atoi(parts[1])
+			firstId, _ := strconv.ParseUint(parts[0], 10, 64)
+			lastId, _ := strconv.ParseUint(parts[1], 10, 64)
 
-			// Check each ID in the range for numbers displayed twice
-			for id := firstId; id <= lastId; id++ {
-				if id < 10 {
-					continue
-				}
-				idStr := strconv.Itoa(id)
-				
-				// Part 1: 
-				// Check if the ID is composed of a number displayed twice
-				// This means the string length should be even and first half equals second half
-				if !part2 && len(idStr)%2 == 0 {
-					mid := len(idStr) / 2
-					firstHalf := idStr[:mid]
-					secondHalf := idStr[mid:]
-					
-					if firstHalf == secondHalf {
-						result += id
-					}
-				}
-				// Part 2:
-				// Check if the ID is composed of a number displayed AT LEAST twice omg
-				if part2 {
-					// Check if the ID is composed of a number displayed at least twice
-					// Find the smallest digit length that divides the string length evenly
-					for d := 1; d < len(idStr); d++ {
-						if len(idStr)%d != 0 {
-							continue
-						}
-						pattern := idStr[:d]
-						isRepeated := true
-
-						for i := d; i < len(idStr); i += d {
-							if i+d > len(idStr) || idStr[i:i+d] != pattern {
-								isRepeated = false
-								break
-							}
-						}
-						if isRepeated {
-							result += id
-							break
-						}
-					}
-				}
+			// Part 1:
+			// IDs made of a number displayed twice
+			query := digitpattern.Exactly(2)
+			// Part 2:
+			// IDs made of a number displayed AT LEAST twice omg
+			if part2 {
+				query = digitpattern.AtLeast(2)
 			}
+			result.Add(result, query.Sum(firstId, lastId))
 		}
 	}
-	// when you're ready to do part 2, remove this "not implemented" block
-	if part2 {
-		return result
-	}
-	// solve part 1 here
 	return result
 }
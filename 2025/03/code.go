@@ -1,13 +1,26 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
-	"strings"
+	"aoc-in-go/harness"
+	"log"
+	"math/big"
+	"os"
 	"strconv"
+	"strings"
 )
 
+// DIGITS=k sets the number of batteries turned on per bank in part 2
+var part2Digits = 12
+
 func main() {
-	aoc.Harness(run)
+	if digits := os.Getenv("DIGITS"); digits != "" {
+		k, err := strconv.Atoi(digits)
+		if err != nil || k < 1 {
+			log.Fatalf("invalid DIGITS %q", digits)
+		}
+		part2Digits = k
+	}
+	harness.Harness(run, harness.PartSetting(2, "digits", strconv.Itoa(part2Digits)))
 }
 
 // on code change, run will be executed 4 times:
@@ -22,50 +35,67 @@ func run(part2 bool, input string) any {
 
 	// when you're ready to do part 2, remove this "not implemented" block
 	if part2 {
-		return part2Fn(lines)
+		return part2Fn(lines, part2Digits)
 	}
 	
 	return part1(lines)
 }
 
-func part1(lines []string) int {
-	result := 0
-	for _, line := range lines {
-		maxJoltage, _ := strconv.Atoi(recursiveFindHighestJoltage(line, 2))
-		result += maxJoltage
-	}
-	return result
+func part1(lines []string) *big.Int {
+	return totalJoltage(lines, 2)
+}
+
+func part2Fn(lines []string, k int) *big.Int {
+	return totalJoltage(lines, k)
 }
 
-func part2Fn(lines []string) int {
-	result := 0
+func totalJoltage(lines []string, k int) *big.Int {
+	result := new(big.Int)
 	for _, line := range lines {
-		// recursively find the highest joltage ( with length - 12)
-		maxJoltage, _ := strconv.Atoi(recursiveFindHighestJoltage(line, 12))
-		result += maxJoltage
+		result.Add(result, selectHighestJoltage(line, k).Value)
 	}
 	return result
 }
 
-func recursiveFindHighestJoltage(line string, length int) string {
-	if length == 0 {
-		return ""
-	}
-	if length >= len(line)  {
-		return line
-	}
+// Selection is the batteries turned on in a bank.
+type Selection struct {
+	Digits  []byte // the chosen digits, in bank order
+	Indices []int  // their positions in the line
+	Value   *big.Int
+}
 
-	maxJoltage := 0
-	maxJoltageIndex := 0
-	for i := 0; i < len(line) - length + 1; i++ {
+// selectHighestJoltage picks the k digits of the line forming the highest
+// number with a monotonic stack: a digit pops the smaller ones before it as
+// long as enough digits remain to fill the k slots. Each digit is pushed and
+// popped at most once, so this is O(n) whatever k, and the value is a big.Int
+// since k can exceed the 18 digits an int holds.
+// When the line has k digits or less, they are all selected.
+func selectHighestJoltage(line string, k int) Selection {
+	digits := make([]int, 0, len(line))
+	for i := 0; i < len(line); i++ {
 		if line[i] >= '0' && line[i] <= '9' {
-			digit := int(line[i] - '0')
-			if digit > maxJoltage {
-				maxJoltage = digit
-				maxJoltageIndex = i
-			}
+			digits = append(digits, i)
+		}
+	}
+
+	stack := make([]int, 0, k)
+	for n, i := range digits {
+		remaining := len(digits) - n
+		for len(stack) > 0 && line[stack[len(stack)-1]] < line[i] && len(stack)-1+remaining >= k {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) < k {
+			stack = append(stack, i)
 		}
 	}
 
-	return strconv.Itoa(maxJoltage) + recursiveFindHighestJoltage(line[maxJoltageIndex + 1:], length-1)
-}
\ No newline at end of file
+	selection := Selection{Indices: stack, Digits: make([]byte, len(stack))}
+	for n, i := range stack {
+		selection.Digits[n] = line[i]
+	}
+	selection.Value = new(big.Int)
+	if len(stack) > 0 {
+		selection.Value.SetString(string(selection.Digits), 10)
+	}
+	return selection
+}
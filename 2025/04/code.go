@@ -1,12 +1,14 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/automaton"
+	"aoc-in-go/grid"
+	"aoc-in-go/harness"
 	"strings"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -24,119 +26,27 @@ func run(part2 bool, input string) any {
 	return partOneFn(input)
 }
 
-func partOneFn(input string) int {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
-	result := 0
-
-	type Cell struct {
-		isRollpaper           bool
-		adjacentRollpaperCount int
-	}
-	
-	grid := make([][]Cell, len(lines))
-	for i, line := range lines {
-		grid[i] = make([]Cell, len(line))
-		for j, char := range line {
-			grid[i][j] = Cell{
-				isRollpaper:           char == '@',
-				adjacentRollpaperCount: 0, 
-			}
-		}
-	}
-	
-	// Count adjacent rollpapers for each cell
-	for i := 0; i < len(grid); i++ {
-		for j := 0; j < len(grid[i]); j++ {
-			count := 0
-			// Check all 8 adjacent positions
-			for di := -1; di <= 1; di++ {
-				for dj := -1; dj <= 1; dj++ {
-					if di == 0 && dj == 0 {
-						continue // Skip the cell itself
-					}
-					ni, nj := i+di, j+dj
-					if ni >= 0 && ni < len(grid) && nj >= 0 && nj < len(grid[ni]) {
-						if grid[ni][nj].isRollpaper {
-							count++
-						}
-					}
-				}
-			}
-			grid[i][j].adjacentRollpaperCount = count
-			if grid[i][j].adjacentRollpaperCount < 4 && grid[i][j].isRollpaper {
-				result++
-			}
-		}
-	}
-	
-	return result
+// Day 4 as a cellular automaton: a roll ('@') with fewer than 4 of its 8
+// neighbors occupied is removed, and removed rolls never come back. Each
+// synchronous step is one round of removals.
+func rollpaperAutomaton(input string) *automaton.Automaton {
+	rolls := automaton.FromGrid(grid.Bools(strings.TrimSpace(input), '@'))
+	rule := automaton.Threshold(automaton.AtLeast(4), automaton.Never)
+	return automaton.New(rolls, automaton.Moore(2, 1), rule, automaton.Synchronous)
 }
 
+// rolls accessible right away
+func partOneFn(input string) int {
+	return rollpaperAutomaton(input).Step()
+}
 
-
+// rolls removed round after round until none is accessible, the removals of
+// each round (wave) being shown with TRACE=1
 func partTwoFn(input string) int {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
 	result := 0
-	
-	type Cell struct {
-		isRollpaper           bool
-		adjacentRollpaperCount int
-	}
-	
-	grid := make([][]Cell, len(lines))
-	for i, line := range lines {
-		grid[i] = make([]Cell, len(line))
-		for j, char := range line {
-			grid[i][j] = Cell{
-				isRollpaper:           char == '@',
-				adjacentRollpaperCount: 0, 
-			}
-		}
-	}
-	
-
-	initialGrid := make([][]Cell, len(grid))
-
-	for {
-		for i := range grid {
-			initialGrid[i] = make([]Cell, len(grid[i]))
-			copy(initialGrid[i], grid[i])
-		}
-		partialResult := 0
-		// Count adjacent rollpapers for each cell
-		for i := 0; i < len(initialGrid); i++ {
-			for j := 0; j < len(initialGrid[i]); j++ {
-				count := 0
-				// Check all 8 adjacent positions
-				for di := -1; di <= 1; di++ {
-					for dj := -1; dj <= 1; dj++ {
-						if di == 0 && dj == 0 {
-							continue // Skip the cell itself
-						}
-						ni, nj := i+di, j+dj
-						if ni >= 0 && ni < len(initialGrid) && nj >= 0 && nj < len(initialGrid[ni]) {
-							if initialGrid[ni][nj].isRollpaper {
-								count++
-							}
-						}
-					}
-				}
-				initialGrid[i][j].adjacentRollpaperCount = count
-				if initialGrid[i][j].adjacentRollpaperCount < 4 && initialGrid[i][j].isRollpaper {
-					partialResult++
-					grid[i][j] = Cell{
-						isRollpaper: false,
-						adjacentRollpaperCount: 0, 
-					}
-				}
-			}
-		}
-		result += partialResult
-
-		if partialResult == 0 {
-			break
-		}
+	for wave, removed := range rollpaperAutomaton(input).Run(0) {
+		harness.Trace().Debug("wave", "index", wave+1, "removed", removed)
+		result += removed
 	}
-	
 	return result
-}
\ No newline at end of file
+}
@@ -1,19 +1,23 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/harness"
+	"aoc-in-go/intervals"
+	"math/big"
 	"strings"
 	"strconv"
-	"sort"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
-type Range struct {
-	start int
-	end   int
+// IngredientReport tells, with REPORT=table or REPORT=json, which of the
+// input ranges (0-based, before merging) cover an ingredient.
+type IngredientReport struct {
+	ID     uint64 `json:"id"`
+	Status string `json:"status"` // fresh or spoiled
+	Ranges []int  `json:"ranges"`
 }
 
 // on code change, run will be executed 4 times:
@@ -24,20 +28,7 @@ type Range struct {
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
 	lines := splitInput(input)
-	availableIngredients := make([]Range, len(strings.Split(lines[0], "\n")))
-	for i, line := range strings.Split(lines[0], "\n") {
-		parts := strings.Split(line, "-")
-		start, _ := strconv.Atoi(parts[0])
-		end, _ :=   strconv.Atoi(parts[1])
-
-		availableIngredients[i] = Range{
-			start: start,
-			end:   end,
-		}
-	}
-	sort.Slice(availableIngredients, func(i, j int) bool {
-		return availableIngredients[i].start < availableIngredients[j].start
-	})
+	availableIngredients := parseRanges(lines[0])
 
 	if part2 {
 		return partTwo(availableIngredients)
@@ -45,68 +36,55 @@ func run(part2 bool, input string) any {
 	return partOne(availableIngredients, lines[1])
 }
 
-func partOne(availableIngredients []Range, freshIngredientsStr string) int {
-	result := 0
-	freshIngredientsArray := strings.Split(freshIngredientsStr, "\n")
-	freshIngredients := make([]int, len(freshIngredientsArray))
-	for i, str := range freshIngredientsArray {
-		freshIngredients[i], _ = strconv.Atoi(str)
+// parseRanges reads the "start-end" lines, both ends included.
+func parseRanges(rangesStr string) []intervals.Interval[uint64] {
+	lines := strings.Split(rangesStr, "\n")
+	ranges := make([]intervals.Interval[uint64], len(lines))
+	for i, line := range lines {
+		parts := strings.Split(line, "-")
+		start, _ := strconv.ParseUint(parts[0], 10, 64)
+		end, _ := strconv.ParseUint(parts[1], 10, 64)
+		ranges[i] = intervals.Closed(start, end)
 	}
-	sort.Ints(freshIngredients)
+	return ranges
+}
 
-	startingSearchIndex := 0
-    for _, freshIngredient := range freshIngredients {
-		for i := startingSearchIndex; i < len(availableIngredients); i++ {
-			availableIngredient := availableIngredients[i]
-				
-			if availableIngredient.start <= freshIngredient && availableIngredient.end >= freshIngredient {
-				result++
-				break
-			}
-			if availableIngredient.start > freshIngredient {
-				break
-			}
-			startingSearchIndex = i
+func partOne(availableIngredients []intervals.Interval[uint64], freshIngredientsStr string) int {
+	result := 0
+	fresh := intervals.New(availableIngredients...)
+	var report []IngredientReport
+	for _, str := range strings.Split(strings.TrimSpace(freshIngredientsStr), "\n") {
+		freshIngredient, _ := strconv.ParseUint(str, 10, 64)
+		isFresh := fresh.Contains(freshIngredient)
+		if isFresh {
+			result++
+		}
+		if harness.Reporting() {
+			report = append(report, reportIngredient(availableIngredients, freshIngredient, isFresh))
 		}
 	}
+	harness.Report(report)
 	return result
 }
 
-func partTwo(availableIngredients []Range) int {
-	result := 0
-	
-	// Merge overlapping ranges
-	merged := []Range{}
-	
-	for _, current := range availableIngredients {
-		if len(merged) == 0 {
-			merged = append(merged, current)
-			continue
-		}
-		
-		last := &merged[len(merged)-1]
-		
-		// If current range overlaps or is adjacent to the last merged range
-		if current.start <= last.end+1 {
-			// Extend the last range if needed
-			if current.end > last.end {
-				last.end = current.end
-			}
-		} else {
-			// No overlap, add as new range
-			merged = append(merged, current)
-		}
+func reportIngredient(availableIngredients []intervals.Interval[uint64], id uint64, isFresh bool) IngredientReport {
+	r := IngredientReport{ID: id, Status: "spoiled", Ranges: []int{}}
+	if isFresh {
+		r.Status = "fresh"
 	}
-	
-	// Calculate total coverage
-	for _, r := range merged {
-		result += r.end - r.start + 1
+	for i, availableIngredient := range availableIngredients {
+		if availableIngredient.Contains(id) {
+			r.Ranges = append(r.Ranges, i)
+		}
 	}
-	
-	return result
+	return r
+}
+
+func partTwo(availableIngredients []intervals.Interval[uint64]) *big.Int {
+	return intervals.New(availableIngredients...).Len()
 }
 
 func splitInput(input string) []string {
 	lines := strings.Split(input, "\n\n")
 	return lines
-}
\ No newline at end of file
+}
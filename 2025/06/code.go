@@ -1,14 +1,17 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
-	"strings"
-	"strconv"	
+	"aoc-in-go/grid"
+	"aoc-in-go/harness"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,106 +21,304 @@ func main() {
 // 4. with: true (part2), and user input
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
-
-	lines := strings.Split(input, "\n")
-	problemCount := len(strings.Fields(lines[0]))
-	linesCount := len(lines)
-	problems := make([][]string, linesCount)
-	for i, line := range lines {
-		problems[i] = make([]string, problemCount)
-		parts := strings.Fields(line)
-		for j, str := range parts {
-			problems[i][j] = str
+	problems := parseWorksheet(input)
+	result := int64(0)
+	for i, problem := range problems {
+		numbers := problem.RowNumbers()
+		if part2 {
+			numbers = problem.ColumnNumbers()
+		}
+		expr, err := problem.Expr(numbers)
+		if err != nil {
+			return fmt.Errorf("problem %d: %w", i, err)
+		}
+		value, err := expr.Eval()
+		if err != nil {
+			return fmt.Errorf("problem %d: %w", i, err)
+		}
+		if harness.Tracing() {
+			harness.Trace().Debug("problem", "index", i, "layout", problem.Cells.Format(layout),
+				"expr", expr.String(), "value", value)
 		}
+		if result, err = checkedAdd(result, value); err != nil {
+			return fmt.Errorf("total: %w", err)
+		}
+	}
+	return result
+}
+
+func layout(char rune) rune {
+	if char == 0 {
+		return ' '
 	}
-	// when you're ready to do part 2, remove this "not implemented" block
-	if part2 {
-		return runPart2(lines);
-	}
-	// solve part 1 here
-	return runPart1(problems);
-}
-
-func runPart1(problems [][]string) int {
-	result := 0
-	
-	for i := 0; i < len(problems[0]); i++ {
-		sum := 0
-		operation := problems[len(problems) -1][i]
-		for j := 0; j < len(problems) - 1; j++ {
-			value, _ := strconv.Atoi(problems[j][i])
-			if operation == "+" {
-				sum += value
-			} else if operation == "*" {
-				if j == 0 {
-					sum = 1
+	return char
+}
+
+// Problem is a block of the worksheet between two all-blank columns: the
+// operator found on the last line, and the characters above it.
+type Problem struct {
+	Operator rune
+	Cells    *grid.Grid[rune]
+}
+
+// parseWorksheet splits the worksheet into problems at the all-blank
+// columns. Lines are padded to the longest one.
+func parseWorksheet(input string) []Problem {
+	worksheet := grid.Runes(input)
+	last := worksheet.Height() - 1
+	var problems []Problem
+	start := 0
+	for j := 0; j <= worksheet.Width(); j++ {
+		if j < worksheet.Width() && !blankColumn(worksheet, j) {
+			continue
+		}
+		if j > start {
+			problem := Problem{Cells: grid.New[rune](last, j-start)}
+			for col := start; col < j; col++ {
+				if char := worksheet.At(grid.Point{Row: last, Col: col}); !blank(char) {
+					problem.Operator = char
+				}
+				for row := 0; row < last; row++ {
+					problem.Cells.Set(grid.Point{Row: row, Col: col - start}, worksheet.At(grid.Point{Row: row, Col: col}))
 				}
-				sum *= value
 			}
+			problems = append(problems, problem)
 		}
-		result += sum
+		start = j + 1
 	}
-	return result
+	return problems
 }
 
-func runPart2(lines []string) int {
-	// Split each line into individual characters
-	charGrid := make([][]string, len(lines))
-	maxLength := 0
-	for i, line := range lines {
-		charGrid[i] = make([]string, len(line))
-		maxLength = max(maxLength, len(line))
-		for j, char := range line {
-			charGrid[i][j] = string(char)
+func blankColumn(worksheet *grid.Grid[rune], j int) bool {
+	for i := 0; i < worksheet.Height(); i++ {
+		if !blank(worksheet.At(grid.Point{Row: i, Col: j})) {
+			return false
 		}
 	}
+	return true
+}
 
-    currentOperation := -1
-	currentOperationResult := 0
-	result := 0
+// blank is a space, or a padding zero rune.
+func blank(char rune) bool {
+	return char == ' ' || char == 0
+}
 
-	for i := 0; i < maxLength; i++ {
-		currentNumber := ""
-		for j := 0; j < len(charGrid); j++ {
-			if i >= len(charGrid[j]) {
-				continue
-			}
-			currentNumber += charGrid[j][i]
+// RowNumbers reads the problem the human way, one number per row.
+func (p Problem) RowNumbers() []int64 {
+	var numbers []int64
+	for i := 0; i < p.Cells.Height(); i++ {
+		if number, ok := readNumber(p.Cells.Row(i)); ok {
+			numbers = append(numbers, number)
 		}
-        
-		if strings.TrimSpace(currentNumber) == "" {
-			continue
+	}
+	return numbers
+}
+
+// ColumnNumbers reads the problem the cephalopod way, one number per column
+// with its most significant digit on top, from the rightmost column.
+func (p Problem) ColumnNumbers() []int64 {
+	var numbers []int64
+	for j := p.Cells.Width() - 1; j >= 0; j-- {
+		column := make([]rune, p.Cells.Height())
+		for i := range column {
+			column[i] = p.Cells.At(grid.Point{Row: i, Col: j})
 		}
+		if number, ok := readNumber(column); ok {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
 
-		lastChar := currentNumber[len(currentNumber) - 1]
-		if lastChar == '+' || lastChar == '*' {
-			// new operation, save previous operation result
-			fmt.Println(lastChar, " found, add ", currentOperationResult, " to result", result)
-			result += currentOperationResult
-
-			cephalNumber := currentNumber[:len(currentNumber)-1]
-			num, _ := strconv.Atoi(strings.TrimSpace(cephalNumber))
-			fmt.Println("Set current to ", cephalNumber)
-			currentOperationResult = num
-			if lastChar == '+' {
-				currentOperation = 0
-			} else {
-				currentOperation = 1
-			}
-		} else {
-			cephalNumber, _ := strconv.Atoi(strings.TrimSpace(currentNumber))
-			fmt.Println("Set current to ", cephalNumber)
-			if currentOperation == 0 {
-				currentOperationResult += cephalNumber
-			} else if currentOperation == 1 {
-				currentOperationResult *= cephalNumber
-			}
+// readNumber reads the digits of chars, ignoring blanks.
+func readNumber(chars []rune) (int64, bool) {
+	var digits strings.Builder
+	for _, char := range chars {
+		if !blank(char) {
+			digits.WriteRune(char)
 		}
-		if i == maxLength - 1 {
-			fmt.Println("Add ", currentOperationResult, " to result", result)
-			result += currentOperationResult
+	}
+	number, err := strconv.ParseInt(digits.String(), 10, 64)
+	return number, err == nil
+}
+
+// Expr builds the expression of the problem: its operator applied to all
+// the numbers, in the order read.
+func (p Problem) Expr(numbers []int64) (Expr, error) {
+	op, ok := operators[p.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q", p.Operator)
+	}
+	if len(numbers) == 0 {
+		return nil, errors.New("no numbers")
+	}
+	operands := make([]Expr, len(numbers))
+	for i, number := range numbers {
+		operands[i] = Number(number)
+	}
+	if op.rightAssoc {
+		expr := operands[len(operands)-1]
+		for i := len(operands) - 2; i >= 0; i-- {
+			expr = Binary{op, operands[i], expr}
 		}
+		return expr, nil
+	}
+	expr := operands[0]
+	for _, operand := range operands[1:] {
+		expr = Binary{op, expr, operand}
 	}
+	return expr, nil
+}
 
-	return result
-}
\ No newline at end of file
+// Expr is a node of a worksheet expression tree.
+type Expr interface {
+	// Eval fails on int64 overflows and invalid operations (division by
+	// zero, negative exponent).
+	Eval() (int64, error)
+	String() string
+}
+
+type Number int64
+
+func (n Number) Eval() (int64, error) {
+	return int64(n), nil
+}
+
+func (n Number) String() string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+// Operator is a binary operator of the worksheet. Operators are left
+// associative (a - b - c is (a - b) - c) except '^', which is right
+// associative (a ^ b ^ c is a ^ (b ^ c)).
+type Operator struct {
+	symbol     rune
+	name       string // min and max are printed as functions, min(a, b)
+	precedence int
+	rightAssoc bool
+	apply      func(a, b int64) (int64, error)
+}
+
+var operators = map[rune]*Operator{
+	'+': {symbol: '+', precedence: 1, apply: checkedAdd},
+	'-': {symbol: '-', precedence: 1, apply: checkedSub},
+	'*': {symbol: '*', precedence: 2, apply: checkedMul},
+	'/': {symbol: '/', precedence: 2, apply: checkedDiv},
+	'^': {symbol: '^', precedence: 3, rightAssoc: true, apply: checkedPow},
+	'<': {symbol: '<', name: "min", apply: func(a, b int64) (int64, error) { return min(a, b), nil }},
+	'>': {symbol: '>', name: "max", apply: func(a, b int64) (int64, error) { return max(a, b), nil }},
+}
+
+type Binary struct {
+	Op          *Operator
+	Left, Right Expr
+}
+
+func (b Binary) Eval() (int64, error) {
+	left, err := b.Left.Eval()
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.Right.Eval()
+	if err != nil {
+		return 0, err
+	}
+	value, err := b.Op.apply(left, right)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", b, err)
+	}
+	return value, nil
+}
+
+// String prints the expression with the parentheses needed only, and nested
+// min/max calls flattened: min(a, b, c).
+func (b Binary) String() string {
+	if b.Op.name != "" {
+		return b.Op.name + "(" + strings.Join(b.arguments(), ", ") + ")"
+	}
+	return b.operand(b.Left, !b.Op.rightAssoc) + " " + string(b.Op.symbol) + " " + b.operand(b.Right, b.Op.rightAssoc)
+}
+
+func (b Binary) arguments() []string {
+	var arguments []string
+	for _, operand := range []Expr{b.Left, b.Right} {
+		if inner, ok := operand.(Binary); ok && inner.Op == b.Op {
+			arguments = append(arguments, inner.arguments()...)
+		} else {
+			arguments = append(arguments, operand.String())
+		}
+	}
+	return arguments
+}
+
+// operand wraps an operand binding less tightly than b, or as tightly when
+// it is not on the side b associates with (the right of '-', the left of '^').
+func (b Binary) operand(operand Expr, assocSide bool) string {
+	inner, ok := operand.(Binary)
+	if !ok || inner.Op.name != "" || inner.Op.precedence > b.Op.precedence ||
+		inner.Op.precedence == b.Op.precedence && assocSide {
+		return operand.String()
+	}
+	return "(" + operand.String() + ")"
+}
+
+var errOverflow = errors.New("int64 overflow")
+
+func checkedAdd(a, b int64) (int64, error) {
+	if b > 0 && a > math.MaxInt64-b || b < 0 && a < math.MinInt64-b {
+		return 0, errOverflow
+	}
+	return a + b, nil
+}
+
+func checkedSub(a, b int64) (int64, error) {
+	if b < 0 && a > math.MaxInt64+b || b > 0 && a < math.MinInt64+b {
+		return 0, errOverflow
+	}
+	return a - b, nil
+}
+
+func checkedMul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	if product/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return 0, errOverflow
+	}
+	return product, nil
+}
+
+// checkedDiv truncates toward zero.
+func checkedDiv(a, b int64) (int64, error) {
+	switch {
+	case b == 0:
+		return 0, errors.New("division by zero")
+	case a == math.MinInt64 && b == -1:
+		return 0, errOverflow
+	}
+	return a / b, nil
+}
+
+func checkedPow(a, b int64) (int64, error) {
+	if b < 0 {
+		return 0, errors.New("negative exponent")
+	}
+	switch {
+	case b == 0 || a == 1:
+		return 1, nil
+	case a == 0:
+		return 0, nil
+	case a == -1:
+		return 1 - 2*(b%2), nil
+	}
+	// |a| >= 2 overflows within 63 multiplications
+	result := int64(1)
+	for ; b > 0; b-- {
+		var err error
+		if result, err = checkedMul(result, a); err != nil {
+			return 0, err
+		}
+	}
+	return result, nil
+}
@@ -1,13 +1,29 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/grid"
+	"aoc-in-go/harness"
 	"strings"
 	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"log"
+	"math"
+	"os"
+	"time"
 )
 
+// BOUNDARY=absorb|wrap|reflect picks what happens to beams split past the
+// sides of the manifold, see Boundary
+var boundary Boundary
+
 func main() {
-	aoc.Harness(run)
+	var err error
+	if boundary, err = parseBoundary(os.Getenv("BOUNDARY")); err != nil {
+		log.Fatal(err)
+	}
+	harness.Harness(run, harness.Setting("boundary", boundary.String()))
 }
 
 // on code change, run will be executed 4 times:
@@ -18,80 +34,252 @@ func main() {
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
 
-	lines := strings.Split(input, "\n")
-	grid := make([][]rune, len(lines))
-	startingPosition := []int{0, 0}
-	for i, line := range lines {
-		grid[i] = []rune(line)
-		if i == 0 {
-			for j, char := range line {
-				if char == 'S' {
-					startingPosition = []int{i, j}
-					break
-				}
-			}
+	manifold := grid.Runes(input)
+	start, _ := manifold.Find(func(char rune) bool { return char == 'S' })
+	// VISUALIZE=beams.gif|ansi records every row of the sweep, see visualize
+	var counts *grid.Grid[int]
+	var onRow func(i int, beams []int)
+	if os.Getenv("VISUALIZE") != "" {
+		counts = grid.New[int](manifold.Height(), manifold.Width())
+		onRow = func(i int, beams []int) {
+			copy(counts.Row(i), beams)
+		}
+	}
+	splits, timelines := sweepBeams(manifold, start, boundary, onRow)
+	if counts != nil {
+		if err := visualize(os.Getenv("VISUALIZE"), part2, manifold, start, counts); err != nil {
+			fmt.Fprintln(os.Stderr, "visualize:", err)
 		}
 	}
 	// when you're ready to do part 2, remove this "not implemented" block
 	if part2 {
-		return runPart2(grid, startingPosition)
+		return timelines
 	}
 	// solve part 1 here
-	return runPart1(grid, startingPosition)
+	return splits
+}
+
+// sweepBeams follows the beams row by row without touching the grid. For each
+// column it keeps how many timelines have a beam there: a splitter sends its
+// count to both neighbouring columns, anything else lets it through. Beams
+// merging in a column just add up, so one pass gives both answers:
+// - splits: the splitters hit at least once (part 1)
+// - timelines: the beams leaving the bottom of the manifold (part 2)
+// onRow, when not nil, is given the beam counts of each row from the start.
+func sweepBeams(manifold *grid.Grid[rune], start grid.Point, boundary Boundary, onRow func(i int, beams []int)) (splits int, timelines int) {
+	width := manifold.Width()
+	beams := make([]int, width)
+	beams[start.Col] = 1
+	if onRow != nil {
+		onRow(start.Row, beams)
+	}
+
+	for i := start.Row + 1; i < manifold.Height(); i++ {
+		next := make([]int, width)
+		for j, count := range beams {
+			if count == 0 {
+				continue
+			}
+			if manifold.At(grid.Point{Row: i, Col: j}) != '^' {
+				next[j] += count
+				continue
+			}
+			splits++
+			for _, side := range []int{j - 1, j + 1} {
+				if column, ok := boundary.column(side, width); ok {
+					next[column] += count
+				}
+			}
+		}
+		beams = next
+		if onRow != nil {
+			onRow(i, beams)
+		}
+	}
+
+	for _, count := range beams {
+		timelines += count
+	}
+	return splits, timelines
+}
+
+// Boundary is the behaviour of a beam split past the first or last column.
+type Boundary int
+
+const (
+	// the beam leaves the manifold and is lost (the default)
+	BoundaryAbsorb Boundary = iota
+	// the beam comes back in on the opposite side
+	BoundaryWrap
+	// the beam bounces off the wall, back into the column it was split from
+	BoundaryReflect
+)
+
+var boundaryNames = []string{"absorb", "wrap", "reflect"}
+
+func (b Boundary) String() string {
+	return boundaryNames[b]
 }
 
-func checkForCollisions(grid [][]rune, i int, j int) bool {
-	if grid[i][j] == '^' || grid[i][j] == 'O' {
-		return true
+func parseBoundary(name string) (Boundary, error) {
+	if name == "" {
+		return BoundaryAbsorb, nil
 	}
-	return false
+	for i, boundaryName := range boundaryNames {
+		if strings.EqualFold(name, boundaryName) {
+			return Boundary(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown boundary %q, expected one of %s", name, strings.Join(boundaryNames, ", "))
 }
 
-func rayGoDown(grid [][]rune, i int, j int, part2 bool, memo map[string]int) int {
-	key := fmt.Sprintf("%d,%d", i, j)
-	if part2 {
-		if val, ok := memo[key]; ok {
-			return val
+// column maps a beam column that may be one step outside [0, width) back in
+// the manifold, ok is false when the beam is lost.
+func (b Boundary) column(j, width int) (int, bool) {
+	if j >= 0 && j < width {
+		return j, true
+	}
+	switch b {
+	case BoundaryWrap:
+		return (j + width) % width, true
+	case BoundaryReflect:
+		if j < 0 {
+			return 0, true
 		}
+		return width - 1, true
 	}
+	return 0, false
+}
 
-	newI := i + 1
-	if (newI == len(grid)) {
-		memo[key] = 1
-		return 1
+// visualize replays the sweep row by row, each cell colored by the number of
+// timelines going through it (on a log scale, the counts grow exponentially):
+// - "ansi" prints the rows to stderr as they are reached
+// - a file name writes an animated GIF, one frame per row, with the part
+//   number added to the name
+func visualize(target string, part2 bool, manifold *grid.Grid[rune], start grid.Point, counts *grid.Grid[int]) error {
+	peak := 0
+	for _, count := range counts.All() {
+		peak = max(peak, count)
 	}
-	if checkForCollisions(grid, newI, j) {
-		if !part2 && grid[newI][j] == 'O' {
-			return 1
+	if target == "ansi" {
+		replayANSI(manifold, start, counts, peak)
+		return nil
+	}
+	suffix := "-part1.gif"
+	if part2 {
+		suffix = "-part2.gif"
+	}
+	return writeGIF(strings.TrimSuffix(target, ".gif")+suffix, manifold, start, counts, peak)
+}
+
+var (
+	emptyColor    = color.RGBA{0x10, 0x10, 0x18, 0xff}
+	splitterColor = color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+	startColor    = color.RGBA{0x40, 0xff, 0x40, 0xff}
+	// heat colors, from a single timeline to the busiest cell
+	heatColors = gradient(32,
+		color.RGBA{0x20, 0x30, 0xa0, 0xff},
+		color.RGBA{0xe0, 0x20, 0x40, 0xff},
+		color.RGBA{0xff, 0xf0, 0x80, 0xff},
+	)
+)
+
+func gradient(steps int, stops ...color.RGBA) []color.RGBA {
+	colors := make([]color.RGBA, steps)
+	for i := range colors {
+		t := float64(i) / float64(steps-1) * float64(len(stops)-1)
+		k := min(int(t), len(stops)-2)
+		f := t - float64(k)
+		mix := func(a, b uint8) uint8 {
+			return uint8(float64(a) + (float64(b)-float64(a))*f)
 		}
-		grid[newI][j + 1] = '|'
-		grid[newI][j - 1] = '|'
-		grid[newI][j] = 'O'
-		val := rayGoDown(grid, newI, j + 1, part2, memo) + rayGoDown(grid, newI, j - 1, part2, memo)
-		memo[key] = val
-		return val
-	}
-	grid[newI][j] = '|'
-	val := rayGoDown(grid, newI, j, part2, memo)
-	memo[key] = val
-	return val
+		from, to := stops[k], stops[k+1]
+		colors[i] = color.RGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), 0xff}
+	}
+	return colors
 }
 
-func runPart1(grid [][]rune, startingPosition []int) int {
-	memo := make(map[string]int)
-	return rayGoDown(grid, startingPosition[0], startingPosition[1], false, memo) - 1
+func cellColor(char rune, count, peak int) color.RGBA {
+	switch {
+	case char == '^':
+		return splitterColor
+	case char == 'S':
+		return startColor
+	case count == 0:
+		return emptyColor
+	}
+	level := math.Log(float64(count)) / math.Log(float64(max(peak, 2)))
+	return heatColors[int(level*float64(len(heatColors)-1))]
 }
 
-func runPart2(grid [][]rune, startingPosition []int) int {
-	memo := make(map[string]int)
-	return rayGoDown(grid, startingPosition[0], startingPosition[1], true, memo)
+const replayDelay = 15 * time.Millisecond
+
+func replayANSI(manifold *grid.Grid[rune], start grid.Point, counts *grid.Grid[int], peak int) {
+	for i := 0; i < manifold.Height(); i++ {
+		var sb strings.Builder
+		for j, char := range manifold.Row(i) {
+			c := cellColor(char, counts.Row(i)[j], peak)
+			fmt.Fprintf(&sb, "\033[48;2;%d;%d;%dm%c", c.R, c.G, c.B, char)
+		}
+		sb.WriteString("\033[0m\n")
+		os.Stderr.WriteString(sb.String())
+		if i >= start.Row {
+			time.Sleep(replayDelay)
+		}
+	}
 }
 
-func echo(grid [][]rune) {
-	for _, row := range grid {
-		for _, char := range row {
-			fmt.Print(string(char))
+const (
+	gifCellSize = 4
+	gifDelay    = 4 // 100ths of a second
+)
+
+func writeGIF(path string, manifold *grid.Grid[rune], start grid.Point, counts *grid.Grid[int], peak int) error {
+	palette := color.Palette{emptyColor, splitterColor, startColor}
+	for _, c := range heatColors {
+		palette = append(palette, c)
+	}
+	width, height := manifold.Width(), manifold.Height()
+	paint := func(img *image.Paletted, i int, withBeams bool) {
+		for j, char := range manifold.Row(i) {
+			count := 0
+			if withBeams {
+				count = counts.Row(i)[j]
+			}
+			index := uint8(palette.Index(cellColor(char, count, peak)))
+			for y := i * gifCellSize; y < (i+1)*gifCellSize; y++ {
+				for x := j * gifCellSize; x < (j+1)*gifCellSize; x++ {
+					img.SetColorIndex(x, y, index)
+				}
+			}
 		}
-		fmt.Println()
 	}
-}
\ No newline at end of file
+
+	// the first frame is the empty manifold, then every frame only redraws
+	// the row the beams just reached on top of the previous ones
+	anim := &gif.GIF{}
+	first := image.NewPaletted(image.Rect(0, 0, width*gifCellSize, height*gifCellSize), palette)
+	for i := 0; i < height; i++ {
+		paint(first, i, false)
+	}
+	anim.Image = append(anim.Image, first)
+	anim.Delay = append(anim.Delay, gifDelay)
+	anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	for i := start.Row; i < height; i++ {
+		frame := image.NewPaletted(image.Rect(0, i*gifCellSize, width*gifCellSize, (i+1)*gifCellSize), palette)
+		paint(frame, i, true)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, gifDelay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
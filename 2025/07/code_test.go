@@ -0,0 +1,91 @@
+package main
+
+import (
+	"aoc-in-go/grid"
+	"testing"
+)
+
+const example = `.......S.......
+...............
+.......^.......
+...............
+......^.^......
+...............
+.....^.^.^.....
+...............
+....^.^...^....
+...............
+...^.^...^.^...
+...............
+..^...^.....^..
+...............
+.^.^.^.^.^...^.
+...............`
+
+// a splitter in the first column, then one in the last column only reached
+// by a wrapped beam
+const leftEdge = `S..
+^..
+..^
+...`
+
+// the mirror image: last column, then first column
+const rightEdge = `..S
+..^
+^..
+...`
+
+func TestSweepBeams(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		boundary  Boundary
+		splits    int
+		timelines int
+	}{
+		{"example absorb", example, BoundaryAbsorb, 21, 40},
+		{"example wrap", example, BoundaryWrap, 21, 40},
+		{"example reflect", example, BoundaryReflect, 21, 40},
+		// the beam split to column -1 is lost
+		{"left absorb", leftEdge, BoundaryAbsorb, 1, 1},
+		// it comes back in column 2, where it is split again, to columns 1
+		// and 3 which wraps to 0
+		{"left wrap", leftEdge, BoundaryWrap, 2, 3},
+		// it stays in column 0, under the splitter
+		{"left reflect", leftEdge, BoundaryReflect, 1, 2},
+		{"right absorb", rightEdge, BoundaryAbsorb, 1, 1},
+		{"right wrap", rightEdge, BoundaryWrap, 2, 3},
+		{"right reflect", rightEdge, BoundaryReflect, 1, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manifold := grid.Runes(test.input)
+			start, ok := manifold.Find(func(char rune) bool { return char == 'S' })
+			if !ok {
+				t.Fatal("no start")
+			}
+			splits, timelines := sweepBeams(manifold, start, test.boundary, nil)
+			if splits != test.splits || timelines != test.timelines {
+				t.Errorf("sweepBeams = %d splits, %d timelines, want %d, %d", splits, timelines, test.splits, test.timelines)
+			}
+		})
+	}
+}
+
+func TestParseBoundary(t *testing.T) {
+	for name, want := range map[string]Boundary{
+		"":        BoundaryAbsorb,
+		"absorb":  BoundaryAbsorb,
+		"WRAP":    BoundaryWrap,
+		"Reflect": BoundaryReflect,
+	} {
+		if got, err := parseBoundary(name); err != nil || got != want {
+			t.Errorf("parseBoundary(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"bounce", "wrap ", "0", "absorbed"} {
+		if got, err := parseBoundary(name); err == nil {
+			t.Errorf("parseBoundary(%q) = %v, want an error", name, got)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"	
+	"aoc-in-go/graphviz"
+	"aoc-in-go/harness"
 	"math"
+	"os"
 	"strings"
 	"strconv"
 	"slices"
@@ -10,7 +12,7 @@ import (
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -74,7 +76,8 @@ func part2Run(points [][]int) int {
 		}
 	}
 
-	fmt.Println(points[edges[right].from],)
+	harness.Trace().Debug("last edge", "from", points[edges[right].from], "to", points[edges[right].to])
+	writeDot(points, edges[:right+1], true)
 
 	return points[edges[right].from][0] * points[edges[right].to][0]
 }
@@ -153,6 +156,8 @@ func part1Run(points [][]int, isExample bool) int {
 		}
 	}
 	
+	writeDot(points, smallestEdges, false)
+
 	// Create graphs by linking edges that share endpoints
 	var graphs []Graph
 	usedEdges := make(map[int]bool)
@@ -228,3 +233,63 @@ func (g *Graph) AddEdge(from, to int, weight int) {
 func (g *Graph) GetEdges() []Edge {
 	return g.edges
 }
+
+// writeDot exports the connections to the file named by the DOT env variable
+// (with the part number added), junction boxes labelled by their coordinates
+// and the edges of the spanning forest drawn in bold.
+func writeDot(points [][]int, edges []Edge, part2 bool) {
+	path := os.Getenv("DOT")
+	if path == "" {
+		return
+	}
+	suffix := "-part1.dot"
+	if part2 {
+		suffix = "-part2.dot"
+	}
+	path = strings.TrimSuffix(path, ".dot") + suffix
+
+	g := graphviz.New("day08", false)
+	for _, edge := range edges {
+		for _, p := range []int{edge.from, edge.to} {
+			node := g.AddNode(strconv.Itoa(p))
+			node.Label = fmt.Sprintf("%d,%d,%d", points[p][0], points[p][1], points[p][2])
+		}
+		g.AddEdge(strconv.Itoa(edge.from), strconv.Itoa(edge.to)).Label = strconv.Itoa(edge.weight)
+	}
+	style := graphviz.Style{}
+	for _, edge := range spanningForest(len(points), edges) {
+		style.HighlightedEdges = append(style.HighlightedEdges, graphviz.Edge{From: strconv.Itoa(edge.from), To: strconv.Itoa(edge.to)})
+	}
+	if err := graphviz.WriteFile(path, g, style); err != nil {
+		fmt.Fprintln(os.Stderr, "dot:", err)
+	}
+}
+
+// spanningForest runs Kruskal on the given edges: the shortest edges joining
+// two different circuits, one tree per circuit.
+func spanningForest(pointCount int, edges []Edge) []Edge {
+	sorted := slices.Clone(edges)
+	slices.SortFunc(sorted, func(a, b Edge) int {
+		return a.weight - b.weight
+	})
+	parent := make([]int, pointCount)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	var forest []Edge
+	for _, edge := range sorted {
+		a, b := find(edge.from), find(edge.to)
+		if a != b {
+			parent[a] = b
+			forest = append(forest, edge)
+		}
+	}
+	return forest
+}
@@ -1,15 +1,15 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/harness"
+	"context"
 	"strings"
 	"strconv"
 	"math"
-	"fmt"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.HarnessContext(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,11 +18,11 @@ func main() {
 // 3. with: false (part1), and user input
 // 4. with: true (part2), and user input
 // the return value of each run is printed to stdout
-func run(part2 bool, input string) any {
+func run(ctx context.Context, part2 bool, input string) any {
 	lines := strings.Split(input, "\n")
 
 	if (lines[0] == "..............") {
-		fmt.Println("skip")
+		harness.Trace().Info("skip example")
 		return 0
 	}
 
@@ -38,7 +38,11 @@ func run(part2 bool, input string) any {
 	}
 	// when you're ready to do part 2, remove this "not implemented" block
 	if part2 {
-		return part2Run(coords)
+		largestArea, err := part2Run(ctx, coords)
+		if err != nil {
+			return err
+		}
+		return largestArea
 	}
 	// solve part 1 here
 	return part1Run(coords)
@@ -64,7 +68,8 @@ func calculateArea(coord1 []int, coord2 []int) int {
 	return width * height
 }
 
-func part2Run(coords [][]int) int {
+// part2Run checks every pair of points, stopping when ctx is done.
+func part2Run(ctx context.Context, coords [][]int) (int, error) {
 	// Convert coords to Point array
 	points := make([]Point, len(coords))
 	for i, coord := range coords {
@@ -72,21 +77,27 @@ func part2Run(coords [][]int) int {
 	}
 	largestArea := 0 
 
-	fmt.Println(len(points))
+	harness.Trace().Debug("polygon", "points", len(points))
 	for i := 0; i < len(points); i++ {
+		if err := ctx.Err(); err != nil {
+			return largestArea, err
+		}
 		for j := i + 1; j < len(points); j++ {
 			width := int(math.Abs(float64(points[i].X - points[j].X))) + 1
 			height := int(math.Abs(float64(points[i].Y - points[j].Y))) + 1
 			
 			area :=  width * height
-			fmt.Println(points[i], points[j], width, height, area, isRectangleInsidePolygon(points[i],  points[j], points))
+			if harness.Tracing() {
+				harness.Trace().Debug("rectangle", "from", points[i], "to", points[j], "width", width, "height", height,
+					"area", area, "inside", isRectangleInsidePolygon(points[i],  points[j], points))
+			}
 			if area > largestArea && isRectangleInsidePolygon(points[i],  points[j], points) {
-				fmt.Println("found")
+				harness.Trace().Debug("largest", "area", area)
 				largestArea = area
 			}
 		}
 	}	
-	return largestArea
+	return largestArea, nil
 }
 
 type Point struct {
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/harness"
 	"github.com/draffensperger/golp"
 	"math"
 	"strings"
@@ -9,7 +9,7 @@ import (
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -1,14 +1,18 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"	
+	"aoc-in-go/graphviz"
+	"aoc-in-go/harness"
 	"strings"
+	"strconv"
 	"fmt"
+	"iter"
+	"os"
 	"slices"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.Harness(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,13 +22,17 @@ func main() {
 // 4. with: true (part2), and user input
 // the return value of each run is printed to stdout
 func run(part2 bool, input string) any {
-	lines := strings.Split(strings.TrimSpace(input), "\n")
+	graph := parseGraph(input)
 
-	// each line defines a node and its connections (format aaa: bbb ccc)
-	graph := make(map[string][]string)
-	for _, line := range lines {
-		parts := strings.Split(line, ": ")
-		graph[parts[0]] = strings.Split(parts[1], " ")
+	// SAMPLE_PATHS=n prints up to n paths of the current part to stderr
+	if n, err := strconv.Atoi(os.Getenv("SAMPLE_PATHS")); err == nil && n > 0 {
+		printSamplePaths(graph, part2, n)
+	}
+	// DOT=file.dot writes the device graph, see writeDot
+	if path := os.Getenv("DOT"); path != "" {
+		if err := writeDot(graph, part2, path); err != nil {
+			fmt.Fprintln(os.Stderr, "dot:", err)
+		}
 	}
 
 	// when you're ready to do part 2, remove this "not implemented" block
@@ -35,6 +43,41 @@ func run(part2 bool, input string) any {
 	return part1Run(graph)
 }
 
+// The input is normally the puzzle adjacency list, but a Graphviz file
+// (as written by writeDot or by hand) is accepted too.
+func parseGraph(input string) map[string][]string {
+	var g *graphviz.Graph
+	var err error
+	if graphviz.IsDOT(input) {
+		g, err = graphviz.Parse(input)
+	} else {
+		g, err = graphviz.ParseAdjacency("day11", input)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return g.Adjacency()
+}
+
+// writeDot exports the graph with the part's waypoints as boxes and the nodes
+// on the path-count DAG (the ones pathsBetween actually walks) filled, the
+// rest dimmed. The part number is added to the file name.
+func writeDot(graph map[string][]string, part2 bool, path string) error {
+	source, waypoints := "you", []string{"you", "out"}
+	if part2 {
+		source, waypoints = "svr", []string{"svr", "dac", "fft", "out"}
+		path = strings.TrimSuffix(path, ".dot") + "-part2.dot"
+	} else {
+		path = strings.TrimSuffix(path, ".dot") + "-part1.dot"
+	}
+	g := graphviz.FromAdjacency("day11", graph)
+	return graphviz.WriteFile(path, g, graphviz.Style{
+		Waypoints:   waypoints,
+		Highlighted: graphviz.OnPaths(graph, source, "out"),
+		Dim:         true,
+	})
+}
+
 func part1Run(graph map[string][]string) int {
 	paths := pathsBetween(graph, make(map[string]int), "you", "out")
 	return paths
@@ -75,7 +118,7 @@ func part2Run(graph map[string][]string) int {
 		secondMidpoint = "dac"
 	} else {
         // Handle case where neither midpoint was reachable (error handling)
-        fmt.Println("Error: Neither 'dac' nor 'fft' found via BFS from 'svr'")
+        harness.Trace().Warn("neither dac nor fft found via BFS from svr")
         return 0
     }
 
@@ -86,53 +129,118 @@ func part2Run(graph map[string][]string) int {
 	return part2Paths
 }
 
-// Old way for part 1 through pure DFS
-// While logging found path on part two, no loop were detected.
-// Since pure DFS was way too slow, I went for memoized DFS (only possible in non cyclic graphs called "DAG")
-func findAllPaths(graph map[string][]string, source, destination string) [][]string {
-	var allPaths [][]string
-	// Use a map to track visited nodes within the current path.
-	// We use a map[string]bool instead of just a slice to make lookups faster (O(1)).
-	visited := make(map[string]bool)
-	// Start the recursive DFS from the source.
-	dfs(graph, source, destination, []string{}, visited, &allPaths)
-	return allPaths
+// PathOptions limits and filters the paths yielded by enumeratePaths.
+type PathOptions struct {
+	MaxCount  int      // stop after yielding this many paths (0 = no limit)
+	MaxLength int      // skip paths with more nodes than this (0 = no limit)
+	Waypoints []string // only yield paths going through all of these nodes
 }
 
-// DFS to find a path from a to b
-func dfs(graph map[string][]string, current, destination string, currentPath []string, visited map[string]bool, allPaths *[][]string) {
-	if slices.Contains(currentPath, current) {
-		fmt.Println("Loop detected: ", current)
-		return
-	}
-	// 1. Mark the current node as visited.
-	visited[current] = true
-	// 2. Add the current node to the path.
-	currentPath = append(currentPath, current)
-
-	// 3. Base case: If the current node is the destination, a path is found.
-	if current == destination {
-		// Create a copy of the current path and append it to the results.
-		// We copy because currentPath is a slice that gets modified in subsequent recursions.
-		pathCopy := make([]string, len(currentPath))
-		copy(pathCopy, currentPath)
-		*allPaths = append(*allPaths, pathCopy)
-	} else {
-		// 4. Recurse for all unvisited neighbors.
-		for _, neighbor := range graph[current] {
-			if !visited[neighbor] {
-				dfs(graph, neighbor, destination, currentPath, visited, allPaths)
+// enumeratePaths yields the paths from source to destination one at a time,
+// with a DFS, so a caller can look at a handful of sample paths and stop.
+// Branches that can no longer reach the destination, or one of the required
+// waypoints, are pruned up front. It replaces the part 1 DFS collecting
+// every path into a [][]string, hopeless once the count reaches the billions
+// (part 2 has ~4e14).
+func enumeratePaths(graph map[string][]string, source, destination string, opts PathOptions) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		// nodes that can still reach the destination / each waypoint
+		reachesEnd := reachableFrom(graph, destination)
+		reachesWaypoint := make([]map[string]bool, len(opts.Waypoints))
+		for i, waypoint := range opts.Waypoints {
+			reachesWaypoint[i] = reachableFrom(graph, waypoint)
+		}
+
+		visited := make(map[string]bool)
+		path := []string{}
+		yielded := 0
+
+		var walk func(current string) bool
+		walk = func(current string) bool {
+			// a node already on the path means a loop, just skip that branch
+			if visited[current] || !reachesEnd[current] {
+				return true
+			}
+			if opts.MaxLength > 0 && len(path) >= opts.MaxLength {
+				return true
 			}
+			visited[current] = true
+			path = append(path, current)
+			defer func() {
+				path = path[:len(path)-1]
+				visited[current] = false
+			}()
+
+			missing := false
+			for i, waypoint := range opts.Waypoints {
+				if visited[waypoint] {
+					continue
+				}
+				if !reachesWaypoint[i][current] {
+					return true
+				}
+				missing = true
+			}
+
+			if current == destination {
+				if missing {
+					return true
+				}
+				if !yield(slices.Clone(path)) {
+					return false
+				}
+				yielded++
+				return opts.MaxCount == 0 || yielded < opts.MaxCount
+			}
+			for _, neighbor := range graph[current] {
+				if !walk(neighbor) {
+					return false
+				}
+			}
+			return true
 		}
+		walk(source)
 	}
+}
 
-	// 5. Backtrack: Unmark the current node as visited and remove it from the path
-	//    so that it can be included in other potential paths.
-	currentPath = currentPath[:len(currentPath)-1]
-	visited[current] = false
+func printSamplePaths(graph map[string][]string, part2 bool, n int) {
+	source, waypoints := "you", []string(nil)
+	if part2 {
+		source, waypoints = "svr", []string{"dac", "fft"}
+	}
+	opts := PathOptions{MaxCount: n, Waypoints: waypoints}
+	for path := range enumeratePaths(graph, source, "out", opts) {
+		fmt.Fprintln(os.Stderr, strings.Join(path, " -> "))
+	}
+}
+
+// reachableFrom returns the set of nodes having at least one path to target
+// (target included), using a BFS on the reversed graph.
+func reachableFrom(graph map[string][]string, target string) map[string]bool {
+	reversed := make(map[string][]string)
+	for node, outputs := range graph {
+		for _, output := range outputs {
+			reversed[output] = append(reversed[output], node)
+		}
+	}
+	reachable := map[string]bool{target: true}
+	queue := []string{target}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, input := range reversed[node] {
+			if !reachable[input] {
+				reachable[input] = true
+				queue = append(queue, input)
+			}
+		}
+	}
+	return reachable
 }
 
-// As explained above, switch to an optimized version of DFS that counts paths from start to end using memoization
+// Enumerating every path (see enumeratePaths) is way too slow for the counts.
+// While logging found paths on part two no loop was detected, so the graph is
+// a DAG and the paths can be counted with a memoized DFS instead.
 // Then to optimize further, the path calculation is split in 3 parts:
 // svr -> firstMidpoint, 
 // firstMidpoint -> secondMidpoint, 
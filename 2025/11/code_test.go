@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const example = `aaa: you hhh
+you: bbb ccc
+bbb: ddd eee
+ccc: ddd eee fff
+ddd: ggg
+eee: out
+fff: out
+ggg: out
+hhh: ccc fff iii
+iii: out`
+
+const example2 = `svr: aaa bbb
+aaa: fft
+fft: ccc
+bbb: tty
+tty: ccc
+ccc: ddd eee
+ddd: hub
+hub: fff
+eee: dac
+dac: fff
+fff: ggg hhh
+ggg: out
+hhh: out`
+
+func TestEnumeratePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		source string
+		opts   PathOptions
+		want   []string
+	}{
+		{"all", example, "you", PathOptions{}, []string{
+			"you bbb ddd ggg out",
+			"you bbb eee out",
+			"you ccc ddd ggg out",
+			"you ccc eee out",
+			"you ccc fff out",
+		}},
+		{"max count", example, "you", PathOptions{MaxCount: 2}, []string{
+			"you bbb ddd ggg out",
+			"you bbb eee out",
+		}},
+		{"max length", example, "you", PathOptions{MaxLength: 4}, []string{
+			"you bbb eee out",
+			"you ccc eee out",
+			"you ccc fff out",
+		}},
+		{"waypoint", example, "you", PathOptions{Waypoints: []string{"ddd"}}, []string{
+			"you bbb ddd ggg out",
+			"you ccc ddd ggg out",
+		}},
+		{"unreachable waypoint", example, "you", PathOptions{Waypoints: []string{"hhh"}}, nil},
+		{"part 2 waypoints", example2, "svr", PathOptions{Waypoints: []string{"dac", "fft"}}, []string{
+			"svr aaa fft ccc eee dac fff ggg out",
+			"svr aaa fft ccc eee dac fff hhh out",
+		}},
+		{"all options", example2, "svr", PathOptions{MaxCount: 1, MaxLength: 9, Waypoints: []string{"fft"}}, []string{
+			"svr aaa fft ccc ddd hub fff ggg out",
+		}},
+	}
+	for _, test := range tests {
+		var got []string
+		for path := range enumeratePaths(parseGraph(test.input), test.source, "out", test.opts) {
+			got = append(got, strings.Join(path, " "))
+		}
+		// the order of the neighbors is the one of the input
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: enumeratePaths = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParts(t *testing.T) {
+	if got := part1Run(parseGraph(example)); got != 5 {
+		t.Errorf("part 1 = %d, want 5", got)
+	}
+	if got := part2Run(parseGraph(example2)); got != 2 {
+		t.Errorf("part 2 = %d, want 2", got)
+	}
+}
@@ -1,15 +1,18 @@
 package main
 
 import (
-	"github.com/jpillora/puzzler/harness/aoc"
+	"aoc-in-go/grid"
+	"aoc-in-go/harness"
+	"context"
 	"strings"
 	"strconv"
 	"fmt"
+	"slices"
 	"sort"
 )
 
 func main() {
-	aoc.Harness(run)
+	harness.HarnessContext(run)
 }
 
 // on code change, run will be executed 4 times:
@@ -18,7 +21,7 @@ func main() {
 // 3. with: false (part1), and user input
 // 4. with: true (part2), and user input
 // the return value of each run is printed to stdout
-func run(part2 bool, input string) any {
+func run(ctx context.Context, part2 bool, input string) any {
 	presents, regions := parseInput(input)
 	
 	// when you're ready to do part 2, remove this "not implemented" block
@@ -26,12 +29,21 @@ func run(part2 bool, input string) any {
 		return part2Run(presents, regions)
 	}
 	// solve part 1 here
-	return part1Run(presents, regions)
+	fitting, err := part1Run(ctx, presents, regions)
+	if err != nil {
+		return err
+	}
+	return fitting
 }
 
-func part1Run(presents []PresentShape, regions []Region) int {
+// part1Run counts the regions where the presents fit, stopping when ctx is
+// done.
+func part1Run(ctx context.Context, presents []PresentShape, regions []Region) (int, error) {
 	result := 0
 	for _, region := range regions {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
 		// get presents from the region
 		presentsInRegion := []PresentInRegion{}
 		maxRequiredArea := 0
@@ -44,17 +56,14 @@ func part1Run(presents []PresentShape, regions []Region) int {
 
 		if maxRequiredArea <= region.width * region.length {
 			// init region grid
-			regionGrid := make([][]bool, region.width)
-			for i := range regionGrid {
-				regionGrid[i] = make([]bool, region.length)
-			}
+			regionGrid := grid.New[bool](region.width, region.length)
 			// Try to fit the shapes in the region.
 			if SolvePacking(presentsInRegion, regionGrid).Success {
 				result++
 			}
 		}
 	}
-	return result
+	return result, nil
 }
 
 func part2Run(presents []PresentShape, regions []Region) int {
@@ -63,7 +72,7 @@ func part2Run(presents []PresentShape, regions []Region) int {
 
 type Placement struct {
     shapeIndex string
-    orientation [][]bool // The specific orientation being used
+    orientation *grid.Grid[bool] // The specific orientation being used
     x, y int             // The position where it was placed
 }
 
@@ -71,7 +80,7 @@ type Placement struct {
 type PackingResult struct {
     Success bool
     PlacedPlacements []Placement // List of all successfully placed items
-    FinalRegion [][]bool
+    FinalRegion *grid.Grid[bool]
     UnplacedShapes []PlaceableShape // List of shapes that couldn't be fitted
 }
 
@@ -79,14 +88,14 @@ type PackingResult struct {
 type PlaceableShape struct {
     // Note: We need a unique ID for tracking, so we'll use the original shape index + instance ID
     instanceID string // e.g., "ShapeA_1", "ShapeA_2"
-    orientations [][][]bool
+    orientations []*grid.Grid[bool]
     size int
 }
 
 // Get all the distinct shape orientations from the PresentShape struct
-func GetOrientations(ps PresentShape) [][][]bool {
+func GetOrientations(ps PresentShape) []*grid.Grid[bool] {
     // Collect all valid orientations into a slice
-    return [][][]bool{
+    return []*grid.Grid[bool]{
         ps.shape, 
         ps.shapeRotated90, ps.shapeRotated180, ps.shapeRotated270,
         ps.shapeFlipped, 
@@ -103,43 +112,17 @@ type PresentInRegion struct {
 
 type PresentShape struct {
 	index int
-	shape [][]bool
-	shapeRotated90 [][]bool
-	shapeRotated180 [][]bool
-	shapeRotated270 [][]bool
-	shapeFlipped [][]bool
-	shapeFlippedRotated90 [][]bool
-	shapeFlippedRotated180 [][]bool
-	shapeFlippedRotated270 [][]bool
+	shape *grid.Grid[bool]
+	shapeRotated90 *grid.Grid[bool]
+	shapeRotated180 *grid.Grid[bool]
+	shapeRotated270 *grid.Grid[bool]
+	shapeFlipped *grid.Grid[bool]
+	shapeFlippedRotated90 *grid.Grid[bool]
+	shapeFlippedRotated180 *grid.Grid[bool]
+	shapeFlippedRotated270 *grid.Grid[bool]
 	shapeSize int
 }
 
-func rotateShape90(shape [][]bool) [][]bool {
-	newShape := make([][]bool, len(shape[0]))
-	for i := range newShape {
-		newShape[i] = make([]bool, len(shape))
-	}
-	for i := range shape {
-		for j := range shape[i] {
-			newShape[j][len(shape) - i - 1] = shape[i][j]
-		}
-	}
-	return newShape
-}
-
-func flipShape(shape [][]bool) [][]bool {
-	newShape := make([][]bool, len(shape))
-	for i := range newShape {
-		newShape[i] = make([]bool, len(shape[i]))
-	}
-	for i := range shape {
-		for j := range shape[i] {
-			newShape[i][j] = shape[i][len(shape[i]) - j - 1]
-		}
-	}
-	return newShape
-}
-
 type Region struct {
 	width int
 	length int
@@ -153,23 +136,16 @@ func parseInput(input string) ([]PresentShape, []Region) {
 	for i, section := range sections[:len(sections) - 1] {
 		lines := strings.Split(section, "\n")
 
-		shape := make([][]bool, len(lines[1:]))
-		shapeSize := 0
-		for i, line := range lines[1:] {
-			shape[i] = []bool{}
-			for _, char := range line {
-				shape[i] = append(shape[i], char == '#')
-			}
-			shapeSize += strings.Count(line, "#")
-		}
+		shape := grid.Bools(strings.Join(lines[1:], "\n"), '#')
+		shapeSize := shape.Count(func(cell bool) bool { return cell })
 		// generate all rotated and flipped shapes to ease checks later on
-		shapeRotated90 := rotateShape90(shape)
-		shapeRotated180 := rotateShape90(shapeRotated90)
-		shapeRotated270 := rotateShape90(shapeRotated180)
-		shapeFlipped := flipShape(shape)
-		shapeFlippedRotated90 := rotateShape90(shapeFlipped)
-		shapeFlippedRotated180 := rotateShape90(shapeFlippedRotated90)
-		shapeFlippedRotated270 := rotateShape90(shapeFlippedRotated180)
+		shapeRotated90 := shape.Rotate90()
+		shapeRotated180 := shapeRotated90.Rotate90()
+		shapeRotated270 := shapeRotated180.Rotate90()
+		shapeFlipped := shape.FlipHorizontal()
+		shapeFlippedRotated90 := shapeFlipped.Rotate90()
+		shapeFlippedRotated180 := shapeFlippedRotated90.Rotate90()
+		shapeFlippedRotated270 := shapeFlippedRotated180.Rotate90()
 
 		index, _ := strconv.Atoi(strings.Trim(lines[0], ":"))
 		presents[i] = PresentShape{
@@ -215,45 +191,28 @@ func parseInput(input string) ([]PresentShape, []Region) {
 	return presents, regions
 }
 
-func printShape(shape [][]bool) {
-	for _, row := range shape {
-		for _, cell := range row {
-			if cell {
-				fmt.Print("#")
-			} else {
-				fmt.Print(".")
-			}
-		}
-		fmt.Println()
-	}
-}
-
-func CanPlace(region [][]bool, shapeOrientation [][]bool, x int, y int) bool {
-    shapeH := len(shapeOrientation)
-    shapeW := len(shapeOrientation[0])
-    regionH := len(region)
-    regionW := len(region[0])
-
-    // Iterate through the shape's local coordinates (i, j)
-    for i := 0; i < shapeH; i++ {
-        for j := 0; j < shapeW; j++ {
+func CanPlace(region *grid.Grid[bool], shapeOrientation *grid.Grid[bool], x int, y int) bool {
+    // Hot loop: walk the rows as slices rather than through All/In/At, which
+    // cost a closure call and bounds checks per cell
+    for i := 0; i < shapeOrientation.Height(); i++ {
+        shapeRow := shapeOrientation.Row(i)
+        // 1. Check Out-of-Bounds (rows): fine as long as the row is empty
+        if y+i >= region.Height() {
+            if slices.Contains(shapeRow, true) {
+                return false
+            }
+            continue
+        }
+        regionRow := region.Row(y + i)
+        for j, cell := range shapeRow {
             // Check if the current shape pixel is 'true' (part of the shape)
-            if shapeOrientation[i][j] {
-                // Calculate the corresponding absolute coordinates (absX, absY)
-                absX := x + j
-                absY := y + i 
-
-                // 1. Check Out-of-Bounds
-                if absY < 0 || absY >= regionH || absX < 0 || absX >= regionW {
-                    // This pixel is out of bounds
-                    return false
-                }
-
-                // 2. Check Overlap (Collision)
-                if region[absY][absX] {
-                    // This pixel is already occupied in the region
-                    return false
-                }
+            if !cell {
+                continue
+            }
+            // 1. Check Out-of-Bounds (columns)
+            // 2. Check Overlap (Collision) with an already occupied pixel
+            if x+j >= len(regionRow) || regionRow[x+j] {
+                return false
             }
         }
     }
@@ -262,16 +221,11 @@ func CanPlace(region [][]bool, shapeOrientation [][]bool, x int, y int) bool {
 }
 
 // SolvePacking attempts to place all shapes in the region using a greedy approach.
-func SolvePacking(shapesToFit []PresentInRegion, region [][]bool) PackingResult {
-    regionH := len(region)
-    regionW := len(region[0])
-    currentRegion := make([][]bool, regionH)
-    
+func SolvePacking(shapesToFit []PresentInRegion, region *grid.Grid[bool]) PackingResult {
+    regionH := region.Height()
+    regionW := region.Width()
     // Deep copy the initial region (which should be empty 'false' values)
-    for i := range region {
-        currentRegion[i] = make([]bool, regionW)
-        copy(currentRegion[i], region[i])
-    }
+    currentRegion := region.Clone()
 
     // --- 1. Preprocess and Sort Shapes ---
     var allShapes []PlaceableShape
@@ -310,14 +264,13 @@ func SolvePacking(shapesToFit []PresentInRegion, region [][]bool) PackingResult
                     
                     if CanPlace(currentRegion, orientation, x, y) {
                         // --- Commit Placement ---
-                        shapeH := len(orientation)
-                        shapeW := len(orientation[0])
-                        
                         // Mark cells as occupied
-                        for i := 0; i < shapeH; i++ {
-                            for j := 0; j < shapeW; j++ {
-                                if orientation[i][j] {
-                                    currentRegion[y+i][x+j] = true
+                        // rows past the region are empty, CanPlace checked it
+                        for i := 0; i < orientation.Height() && y+i < regionH; i++ {
+                            regionRow := currentRegion.Row(y + i)
+                            for j, cell := range orientation.Row(i) {
+                                if cell {
+                                    regionRow[x+j] = true
                                 }
                             }
                         }
@@ -0,0 +1,286 @@
+// Package aocstub is a local Advent of Code server, for running the network
+// features offline: point them at it with AOC_BASE_URL=<Server.URL>.
+//
+// It serves the puzzle pages (/<year>/day/<day>, part 2 once part 1 is
+// solved), the inputs (/<year>/day/<day>/input) and the answer endpoint
+// (POST /<year>/day/<day>/answer), with the wording of adventofcode.com.
+package aocstub
+
+import (
+	"fmt"
+	"html"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Puzzle is the day of a year served by the stub.
+type Puzzle struct {
+	Year, Day int
+	// Part1 and Part2 are the HTML of the <article> of each part, such as
+	// "<h2>--- Day 1: ... ---</h2><p>...</p>".
+	Part1, Part2 string
+	Input        string
+	Answers      [2]string
+	Solved       int // number of parts already solved
+}
+
+// Verdict is the reply to an answer.
+type Verdict string
+
+const (
+	Correct    Verdict = "correct"
+	TooHigh    Verdict = "too high"
+	TooLow     Verdict = "too low"
+	Wrong      Verdict = "wrong"
+	Cooldown   Verdict = "cooldown"    // answered too recently
+	WrongLevel Verdict = "wrong level" // part already solved, or part 1 is not
+)
+
+// Submission is an answer received by the stub.
+type Submission struct {
+	Year, Day, Part int
+	Answer          string
+	Verdict         Verdict
+	Time            time.Time
+}
+
+// Server is the stub, listening on URL once started.
+type Server struct {
+	*httptest.Server
+
+	// Session is the session cookie accepted, any when empty.
+	Session string
+	// Judge overrides the verdicts, those compared with Puzzle.Answers
+	// otherwise. Correct solves the part, Cooldown replies as if answered
+	// too recently.
+	Judge func(p *Puzzle, part int, answer string) Verdict
+	// Cooldown is the wait after a wrong answer, none when 0
+	// (adventofcode.com starts at one minute).
+	Cooldown time.Duration
+	// RequestsPerMinute limits the requests, answered 429 Too Many Requests
+	// above it, none when 0.
+	RequestsPerMinute int
+	// Now is the clock of the cooldowns and rate limits, time.Now when nil.
+	Now func() time.Time
+
+	mu          sync.Mutex
+	puzzles     map[[2]int]*Puzzle
+	until       time.Time // end of the cooldown
+	requests    []time.Time
+	submissions []Submission
+}
+
+// New starts a stub serving puzzles.
+func New(puzzles ...*Puzzle) *Server {
+	s := NewUnstarted(puzzles...)
+	s.Start()
+	return s
+}
+
+// NewUnstarted returns a stub which is not started yet, so that it can be
+// configured (or its listener replaced) first.
+func NewUnstarted(puzzles ...*Puzzle) *Server {
+	s := &Server{puzzles: map[[2]int]*Puzzle{}}
+	for _, p := range puzzles {
+		s.puzzles[[2]int{p.Year, p.Day}] = p
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{year}/day/{day}", s.servePuzzle)
+	mux.HandleFunc("GET /{year}/day/{day}/input", s.serveInput)
+	mux.HandleFunc("POST /{year}/day/{day}/answer", s.serveAnswer)
+	s.Server = httptest.NewUnstartedServer(s.limit(mux))
+	return s
+}
+
+// Submissions returns the answers received so far.
+func (s *Server) Submissions() []Submission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Submission(nil), s.submissions...)
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *Server) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		now := s.now()
+		i := 0
+		for i < len(s.requests) && now.Sub(s.requests[i]) >= time.Minute {
+			i++
+		}
+		s.requests = append(s.requests[i:], now)
+		limited := s.RequestsPerMinute > 0 && len(s.requests) > s.RequestsPerMinute
+		s.mu.Unlock()
+		if limited {
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// puzzle returns the puzzle of a request, replying 404 when unknown.
+func (s *Server) puzzle(w http.ResponseWriter, r *http.Request) *Puzzle {
+	year, _ := strconv.Atoi(r.PathValue("year"))
+	day, _ := strconv.Atoi(r.PathValue("day"))
+	p := s.puzzles[[2]int{year, day}]
+	if p == nil {
+		http.Error(w, "404 Not Found", http.StatusNotFound)
+	}
+	return p
+}
+
+func (s *Server) loggedIn(r *http.Request) bool {
+	c, err := r.Cookie("session")
+	return err == nil && c.Value != "" && (s.Session == "" || c.Value == s.Session)
+}
+
+func (s *Server) servePuzzle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.puzzle(w, r)
+	if p == nil {
+		return
+	}
+	loggedIn := s.loggedIn(r)
+	var body strings.Builder
+	fmt.Fprintf(&body, `<article class="day-desc">%s</article>`, p.Part1)
+	if loggedIn && p.Solved >= 1 {
+		fmt.Fprintf(&body, "\n<p>Your puzzle answer was <code>%s</code>.</p>", html.EscapeString(p.Answers[0]))
+		fmt.Fprintf(&body, `<article class="day-desc">%s</article>`, p.Part2)
+	}
+	if loggedIn && p.Solved >= 2 {
+		fmt.Fprintf(&body, "\n<p>Your puzzle answer was <code>%s</code>.</p>", html.EscapeString(p.Answers[1]))
+		body.WriteString("\n<p class=\"day-success\">Both parts of this puzzle are complete! They provide two gold stars: **</p>")
+	}
+	writePage(w, fmt.Sprintf("Day %d - Advent of Code %d", p.Day, p.Year), body.String())
+}
+
+func (s *Server) serveInput(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.puzzle(w, r)
+	if p == nil {
+		return
+	}
+	if !s.loggedIn(r) {
+		http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, p.Input)
+}
+
+func (s *Server) serveAnswer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.puzzle(w, r)
+	if p == nil {
+		return
+	}
+	if !s.loggedIn(r) {
+		http.Error(w, "To play, please identify yourself via one of these services.", http.StatusBadRequest)
+		return
+	}
+	part, err := strconv.Atoi(r.PostFormValue("level"))
+	if err != nil || part < 1 || part > 2 {
+		http.Error(w, "invalid level", http.StatusBadRequest)
+		return
+	}
+	answer := strings.TrimSpace(r.PostFormValue("answer"))
+	now := s.now()
+	v := s.verdict(p, part, answer, now)
+	s.submissions = append(s.submissions, Submission{p.Year, p.Day, part, answer, v, now})
+	back := fmt.Sprintf(`<a href="/%d/day/%d">[Return to Day %d]</a>`, p.Year, p.Day, p.Day)
+	var message string
+	switch v {
+	case Correct:
+		p.Solved = part
+		message = `That's the right answer!  You are <span class="day-success">one gold star</span> closer to finishing. ` + back
+	case TooHigh, TooLow, Wrong:
+		message = "That's not the right answer"
+		if v != Wrong {
+			message += "; your answer is " + string(v)
+		}
+		message += ".  If you're stuck, make sure you're using the full input data."
+		if s.Cooldown > 0 {
+			s.until = now.Add(s.Cooldown)
+			message += fmt.Sprintf(" Please wait %s before trying again.", waitString(s.Cooldown))
+		}
+		message += " " + back
+	case Cooldown:
+		message = fmt.Sprintf("You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have %s left to wait. %s", leftString(max(s.until.Sub(now), 0)), back)
+	case WrongLevel:
+		message = "You don't seem to be solving the right level.  Did you already complete it? " + back
+	}
+	writePage(w, fmt.Sprintf("Day %d - Advent of Code %d", p.Day, p.Year), "<article><p>"+message+"</p></article>")
+}
+
+// verdict judges an answer, the cooldown and the level first.
+func (s *Server) verdict(p *Puzzle, part int, answer string, now time.Time) Verdict {
+	switch {
+	case now.Before(s.until):
+		return Cooldown
+	case part != p.Solved+1:
+		return WrongLevel
+	case s.Judge != nil:
+		return s.Judge(p, part, answer)
+	}
+	return Judge(p.Answers[part-1], answer)
+}
+
+// Judge compares an answer with the expected one, numerically when both are
+// integers.
+func Judge(expected, answer string) Verdict {
+	if answer == expected {
+		return Correct
+	}
+	e, ok1 := new(big.Int).SetString(expected, 10)
+	a, ok2 := new(big.Int).SetString(answer, 10)
+	switch {
+	case !ok1 || !ok2:
+		return Wrong
+	case a.Cmp(e) > 0:
+		return TooHigh
+	}
+	return TooLow
+}
+
+// waitString formats the wait after a wrong answer: "one minute",
+// "5 minutes", or "30 seconds" for the waits which are not whole minutes
+// (adventofcode.com has none, see wrongWait in cmd/aoc).
+func waitString(d time.Duration) string {
+	n, unit := int(d/time.Minute), "minute"
+	if d%time.Minute != 0 {
+		n, unit = int(d.Round(time.Second)/time.Second), "second"
+	}
+	if n == 1 {
+		return "one " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// leftString formats the wait left in a cooldown: "1m 5s" or "35s".
+func leftString(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 60 {
+		return fmt.Sprintf("%dm %ds", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+func writePage(w http.ResponseWriter, title, main string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html lang=\"en-us\">\n<head>\n<meta charset=\"utf-8\"/>\n<title>%s</title>\n</head>\n<body>\n<main>\n%s\n</main>\n</body>\n</html>\n", title, main)
+}
@@ -0,0 +1,48 @@
+package aocstub
+
+import (
+	"aoc-in-go/harness"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// LoadPuzzles reads the puzzles of a fixtures directory, laid out as the
+// days of this repository:
+//
+//	<dir>/<year>/<day>/part1.html     the article of part 1
+//	<dir>/<year>/<day>/part2.html     the article of part 2, optional
+//	<dir>/<year>/<day>/input.txt      the input, optional
+//	<dir>/<year>/<day>/answers.txt    part1: <answer> and part2: <answer>
+func LoadPuzzles(dir string) ([]*Puzzle, error) {
+	days, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9][0-9]", "[0-9][0-9]", "part1.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no <year>/<day>/part1.html in %s", dir)
+	}
+	var puzzles []*Puzzle
+	for _, path := range days {
+		dayDir := filepath.Dir(path)
+		p := &Puzzle{}
+		p.Year, _ = strconv.Atoi(filepath.Base(filepath.Dir(dayDir)))
+		p.Day, _ = strconv.Atoi(filepath.Base(dayDir))
+		for name, field := range map[string]*string{"part1.html": &p.Part1, "part2.html": &p.Part2, "input.txt": &p.Input} {
+			b, err := os.ReadFile(filepath.Join(dayDir, name))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			*field = string(b)
+		}
+		answers, err := harness.ReadAnswers(filepath.Join(dayDir, "answers.txt"))
+		if err != nil {
+			return nil, err
+		}
+		p.Answers = [2]string{answers["1"], answers["2"]}
+		puzzles = append(puzzles, p)
+	}
+	return puzzles, nil
+}
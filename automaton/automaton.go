@@ -0,0 +1,232 @@
+package automaton
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Rule gives the next state of a cell from its state and its number of live
+// neighbors.
+type Rule func(alive bool, neighbors int) bool
+
+// Span is an inclusive range of neighbor counts.
+type Span struct {
+	Min, Max int
+}
+
+// Never is the empty span.
+var Never = Span{Min: 1, Max: 0}
+
+func AtLeast(n int) Span {
+	return Span{Min: n, Max: math.MaxInt}
+}
+
+func AtMost(n int) Span {
+	return Span{Min: 0, Max: n}
+}
+
+func (s Span) Contains(n int) bool {
+	return s.Min <= n && n <= s.Max
+}
+
+// Threshold is the rule where a live cell survives when its live neighbor
+// count is in survive, and a dead cell comes alive when it is in birth.
+// Day 4 is Threshold(AtLeast(4), Never), Conway's life is
+// Threshold(Span{2, 3}, Span{3, 3}).
+func Threshold(survive, birth Span) Rule {
+	return func(alive bool, neighbors int) bool {
+		if alive {
+			return survive.Contains(neighbors)
+		}
+		return birth.Contains(neighbors)
+	}
+}
+
+// Update is how the cells of a step are updated.
+type Update int
+
+const (
+	// every cell of a step sees the grid as it was before the step
+	Synchronous Update = iota
+	// cells are updated one at a time in grid order, and see the changes
+	// already made during the step
+	Asynchronous
+)
+
+// Automaton evolves a grid step by step. It keeps the live neighbor count of
+// every cell and, after the first step, only looks at the cells around the
+// ones that changed, so a step costs what changed rather than the whole grid.
+type Automaton struct {
+	grid         *Grid
+	neighborhood Neighborhood
+	rule         Rule
+	update       Update
+
+	neighbors []int  // live neighbor count per cell
+	pending   []int  // cells to look at during the next step
+	queued    []bool // cells already in pending
+	coords    []int  // scratch coordinates
+
+	// asynchronous steps: the cell being updated, -1 outside of a step, and
+	// the cells to look at after it during the same step
+	at    int
+	ahead indexHeap
+	due   []bool // cells already in ahead
+}
+
+// New creates an automaton running on grid, which it modifies in place.
+func New(grid *Grid, neighborhood Neighborhood, rule Rule, update Update) *Automaton {
+	a := &Automaton{
+		grid:         grid,
+		neighborhood: neighborhood,
+		rule:         rule,
+		update:       update,
+		neighbors:    make([]int, grid.Len()),
+		queued:       make([]bool, grid.Len()),
+		coords:       make([]int, len(grid.dims)),
+		at:           -1,
+	}
+	if update == Asynchronous {
+		a.due = make([]bool, grid.Len())
+	}
+	for cell, alive := range grid.cells {
+		if alive {
+			a.forEachAffected(cell, func(affected int) {
+				a.neighbors[affected]++
+			})
+		}
+		a.pending = append(a.pending, cell)
+		a.queued[cell] = true
+	}
+	return a
+}
+
+func (a *Automaton) Grid() *Grid {
+	return a.grid
+}
+
+// Step runs one step and returns the number of cells that changed.
+func (a *Automaton) Step() int {
+	current := a.pending
+	a.pending = nil
+	for _, cell := range current {
+		a.queued[cell] = false
+	}
+
+	if a.update == Asynchronous {
+		// a flip queues the cells it affects further in grid order for this
+		// step (see queue), so they are visited in order as the heap grows
+		a.ahead = current
+		for _, cell := range current {
+			a.due[cell] = true
+		}
+		heap.Init(&a.ahead)
+		changed := 0
+		for len(a.ahead) > 0 {
+			cell := heap.Pop(&a.ahead).(int)
+			a.due[cell] = false
+			a.at = cell
+			alive := a.grid.cells[cell]
+			if a.rule(alive, a.neighbors[cell]) != alive {
+				a.flip(cell)
+				changed++
+			}
+		}
+		a.at = -1
+		return changed
+	}
+
+	var changes []int
+	for _, cell := range current {
+		alive := a.grid.cells[cell]
+		if a.rule(alive, a.neighbors[cell]) != alive {
+			changes = append(changes, cell)
+		}
+	}
+	for _, cell := range changes {
+		a.flip(cell)
+	}
+	return len(changes)
+}
+
+// Run steps until nothing changes, or maxSteps when it is positive, and
+// returns the number of changed cells of each step.
+func (a *Automaton) Run(maxSteps int) []int {
+	var changes []int
+	for maxSteps <= 0 || len(changes) < maxSteps {
+		changed := a.Step()
+		if changed == 0 {
+			break
+		}
+		changes = append(changes, changed)
+	}
+	return changes
+}
+
+// flip changes a cell, updates the counts of the cells it is a neighbor of
+// and queues them, along with itself, for the next step.
+func (a *Automaton) flip(cell int) {
+	alive := !a.grid.cells[cell]
+	a.grid.cells[cell] = alive
+	a.queue(cell)
+	a.forEachAffected(cell, func(affected int) {
+		if alive {
+			a.neighbors[affected]++
+		} else {
+			a.neighbors[affected]--
+		}
+		a.queue(affected)
+	})
+}
+
+// queue has a cell looked at during the next step, or later during the
+// current asynchronous step when it comes after the cell being updated.
+func (a *Automaton) queue(cell int) {
+	if a.at >= 0 && cell > a.at {
+		if !a.due[cell] {
+			a.due[cell] = true
+			heap.Push(&a.ahead, cell)
+		}
+		return
+	}
+	if !a.queued[cell] {
+		a.queued[cell] = true
+		a.pending = append(a.pending, cell)
+	}
+}
+
+// forEachAffected calls fn with every in-bounds cell having cell as neighbor,
+// i.e. cell minus each offset (the neighborhood does not have to be symmetric).
+func (a *Automaton) forEachAffected(cell int, fn func(int)) {
+	a.grid.coords(cell, a.coords)
+	for _, offset := range a.neighborhood {
+		index := 0
+		inBounds := true
+		for d, o := range offset {
+			c := a.coords[d] - o
+			if c < 0 || c >= a.grid.dims[d] {
+				inBounds = false
+				break
+			}
+			index += c * a.grid.strides[d]
+		}
+		if inBounds {
+			fn(index)
+		}
+	}
+}
+
+// indexHeap is a min-heap of cell indexes, i.e. of cells in grid order.
+type indexHeap []int
+
+func (h indexHeap) Len() int           { return len(h) }
+func (h indexHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h indexHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *indexHeap) Push(x any)        { *h = append(*h, x.(int)) }
+
+func (h *indexHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,88 @@
+package automaton
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// sweep is the reference step: every cell in grid order, reading the grid
+// as it was before the step (synchronous) or as it is being updated
+// (asynchronous).
+func sweep(g *Grid, neighborhood Neighborhood, rule Rule, update Update) int {
+	before := g
+	if update == Synchronous {
+		before = g.Clone()
+	}
+	coords := make([]int, len(g.dims))
+	neighbor := make([]int, len(g.dims))
+	changed := 0
+	for cell := range g.cells {
+		g.coords(cell, coords)
+		live := 0
+		for _, offset := range neighborhood {
+			for d := range coords {
+				neighbor[d] = coords[d] + offset[d]
+			}
+			if before.Get(neighbor...) {
+				live++
+			}
+		}
+		if alive := before.cells[cell]; rule(alive, live) != alive {
+			g.cells[cell] = !alive
+			changed++
+		}
+	}
+	return changed
+}
+
+func TestStepAsynchronous(t *testing.T) {
+	// on step 2, the removals of the first rows make the cells after them
+	// removable on that same step, though nothing around those changed on
+	// step 1
+	g := Parse("###.\n####\n..##", '#')
+	a := New(g.Clone(), Moore(2, 1), Threshold(AtLeast(4), Never), Asynchronous)
+	for step, want := range []int{3, 6, 0} {
+		if changed := a.Step(); changed != want {
+			t.Errorf("step %d changed %d cells, want %d", step+1, changed, want)
+		}
+	}
+}
+
+func TestStepMatchesSweep(t *testing.T) {
+	conway := Threshold(Span{2, 3}, Span{3, 3})
+	day4 := Threshold(AtLeast(4), Never)
+	tests := []struct {
+		name         string
+		dims         []int
+		neighborhood Neighborhood
+		rule         Rule
+	}{
+		{"day 4", []int{12, 15}, Moore(2, 1), day4},
+		{"conway", []int{12, 15}, Moore(2, 1), conway},
+		{"von neumann", []int{10, 10}, VonNeumann(2, 1), Threshold(Span{1, 2}, Span{2, 2})},
+		{"radius 2", []int{10, 10}, Moore(2, 2), Threshold(Span{6, 12}, Span{7, 9})},
+		{"asymmetric", []int{10, 10}, Custom([]int{0, 1}, []int{1, 1}, []int{-1, 0}), Threshold(Span{1, 2}, Span{2, 2})},
+		{"3D", []int{4, 6, 6}, Moore(3, 1), Threshold(Span{5, 7}, Span{6, 6})},
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, test := range tests {
+		for _, update := range []Update{Synchronous, Asynchronous} {
+			for range 20 {
+				g := NewGrid(test.dims...)
+				for cell := range g.cells {
+					g.cells[cell] = rng.IntN(2) == 0
+				}
+				start := g.String()
+				want := g.Clone()
+				a := New(g, test.neighborhood, test.rule, update)
+				for step := range 30 {
+					wantChanged := sweep(want, test.neighborhood, test.rule, update)
+					if changed := a.Step(); changed != wantChanged || g.String() != want.String() {
+						t.Fatalf("%s, update %d, from\n%s\nstep %d changed %d cells, want %d:\n%s\nwant\n%s",
+							test.name, update, start, step+1, changed, wantChanged, g, want)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package automaton runs threshold cellular automata on 2D or 3D grids of
+// live/dead cells, as in day 4 where a roll of paper with fewer than 4 of
+// its 8 neighbors occupied gets removed.
+package automaton
+
+import (
+	"aoc-in-go/grid"
+	"fmt"
+	"strings"
+)
+
+// Grid is a dense grid of live/dead cells with 2 dimensions (rows, columns)
+// or 3 (layers, rows, columns). Cells outside of it are always dead.
+type Grid struct {
+	dims    []int
+	strides []int
+	cells   []bool
+}
+
+func NewGrid(dims ...int) *Grid {
+	if len(dims) != 2 && len(dims) != 3 {
+		panic(fmt.Sprintf("automaton: grids have 2 or 3 dimensions, got %d", len(dims)))
+	}
+	g := &Grid{dims: dims, strides: make([]int, len(dims))}
+	size := 1
+	for d := len(dims) - 1; d >= 0; d-- {
+		g.strides[d] = size
+		size *= dims[d]
+	}
+	g.cells = make([]bool, size)
+	return g
+}
+
+// Parse reads a 2D grid, one row per line, where the alive rune marks a live
+// cell. Several 2D layers separated by blank lines make a 3D grid. The grid
+// is sized from the longest row and the tallest layer, the missing cells of
+// the others being dead.
+func Parse(input string, alive rune) *Grid {
+	layers := strings.Split(strings.TrimSpace(input), "\n\n")
+	height, width := 0, 0
+	for _, layer := range layers {
+		rows := strings.Split(layer, "\n")
+		height = max(height, len(rows))
+		for _, row := range rows {
+			width = max(width, len([]rune(row)))
+		}
+	}
+	var g *Grid
+	if len(layers) == 1 {
+		g = NewGrid(height, width)
+	} else {
+		g = NewGrid(len(layers), height, width)
+	}
+	for l, layer := range layers {
+		for i, row := range strings.Split(layer, "\n") {
+			for j, char := range []rune(row) {
+				if char != alive {
+					continue
+				}
+				if len(layers) == 1 {
+					g.Set(true, i, j)
+				} else {
+					g.Set(true, l, i, j)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// FromGrid copies a 2D grid, true cells being alive.
+func FromGrid(g *grid.Grid[bool]) *Grid {
+	a := NewGrid(g.Height(), g.Width())
+	for p, alive := range g.All() {
+		a.cells[p.Row*g.Width()+p.Col] = alive
+	}
+	return a
+}
+
+// Dims returns the size of each dimension.
+func (g *Grid) Dims() []int {
+	return g.dims
+}
+
+// Len returns the number of cells.
+func (g *Grid) Len() int {
+	return len(g.cells)
+}
+
+// Get returns the cell at the given coordinates, false when out of bounds.
+func (g *Grid) Get(coords ...int) bool {
+	index, ok := g.index(coords)
+	return ok && g.cells[index]
+}
+
+// Set sets the cell at the given coordinates, which must be in bounds.
+func (g *Grid) Set(alive bool, coords ...int) {
+	index, ok := g.index(coords)
+	if !ok {
+		panic(fmt.Sprintf("automaton: %v is out of the %v grid", coords, g.dims))
+	}
+	g.cells[index] = alive
+}
+
+// Live returns the number of live cells.
+func (g *Grid) Live() int {
+	live := 0
+	for _, alive := range g.cells {
+		if alive {
+			live++
+		}
+	}
+	return live
+}
+
+func (g *Grid) Clone() *Grid {
+	return &Grid{
+		dims:    append([]int(nil), g.dims...),
+		strides: append([]int(nil), g.strides...),
+		cells:   append([]bool(nil), g.cells...),
+	}
+}
+
+// String prints live cells as '#' and dead ones as '.', 3D layers separated
+// by blank lines.
+func (g *Grid) String() string {
+	var sb strings.Builder
+	width := g.dims[len(g.dims)-1]
+	for index, alive := range g.cells {
+		if index > 0 && index%width == 0 {
+			sb.WriteByte('\n')
+			if len(g.dims) == 3 && index%g.strides[0] == 0 {
+				sb.WriteByte('\n')
+			}
+		}
+		if alive {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+func (g *Grid) index(coords []int) (int, bool) {
+	if len(coords) != len(g.dims) {
+		panic(fmt.Sprintf("automaton: %d coordinates for a %dD grid", len(coords), len(g.dims)))
+	}
+	index := 0
+	for d, c := range coords {
+		if c < 0 || c >= g.dims[d] {
+			return 0, false
+		}
+		index += c * g.strides[d]
+	}
+	return index, true
+}
+
+func (g *Grid) coords(index int, coords []int) {
+	for d, stride := range g.strides {
+		coords[d] = index / stride
+		index %= stride
+	}
+}
@@ -0,0 +1,70 @@
+package automaton
+
+import "fmt"
+
+// Neighborhood is the set of offsets, relative to a cell, of its neighbors.
+type Neighborhood [][]int
+
+// Moore is every cell within the given Chebyshev distance: the 8 surrounding
+// cells in 2D with radius 1, 26 in 3D.
+func Moore(dims, radius int) Neighborhood {
+	return offsets(dims, radius, func(offset []int) bool {
+		return true
+	})
+}
+
+// VonNeumann is every cell within the given Manhattan distance: the 4
+// orthogonal cells in 2D with radius 1, 6 in 3D.
+func VonNeumann(dims, radius int) Neighborhood {
+	return offsets(dims, radius, func(offset []int) bool {
+		distance := 0
+		for _, o := range offset {
+			distance += abs(o)
+		}
+		return distance <= radius
+	})
+}
+
+// Custom uses the given offsets, each one having a coordinate per dimension.
+func Custom(offsets ...[]int) Neighborhood {
+	for _, offset := range offsets {
+		if len(offset) != len(offsets[0]) {
+			panic(fmt.Sprintf("automaton: offset %v has not %d dimensions", offset, len(offsets[0])))
+		}
+	}
+	return Neighborhood(offsets)
+}
+
+// offsets lists all the offsets in [-radius, radius]^dims, but the cell
+// itself, accepted by keep.
+func offsets(dims, radius int, keep func(offset []int) bool) Neighborhood {
+	var n Neighborhood
+	offset := make([]int, dims)
+	var walk func(d int)
+	walk = func(d int) {
+		if d == dims {
+			for _, o := range offset {
+				if o != 0 {
+					if keep(offset) {
+						n = append(n, append([]int(nil), offset...))
+					}
+					return
+				}
+			}
+			return
+		}
+		for o := -radius; o <= radius; o++ {
+			offset[d] = o
+			walk(d + 1)
+		}
+	}
+	walk(0)
+	return n
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"aoc-in-go/harness"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// client talks to adventofcode.com, or to AOC_BASE_URL (see
+// harness.BaseURL), with the session of AOC_SESSION.
+type client struct {
+	baseURL string
+	session string
+	http    *http.Client
+}
+
+func newClient() (*client, error) {
+	session := os.Getenv("AOC_SESSION")
+	if session == "" {
+		return nil, errors.New("AOC_SESSION is not set, see the Session section of the README")
+	}
+	return &client{
+		baseURL: harness.BaseURL(),
+		session: session,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// submit posts the answer of a part, returning the text of the response.
+func (c *client) submit(year, day int, part, answer string) (string, error) {
+	form := url.Values{"level": {part}, "answer": {answer}}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%d/day/%d/answer", c.baseURL, year, day), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	page, err := c.do(req)
+	return articleText(page), err
+}
+
+// "Your puzzle answer was <code>1123</code>."
+var solvedAnswer = regexp.MustCompile(`Your puzzle answer was <code>([^<]*)</code>`)
+
+// completion returns the answers of the parts of a day already solved, as
+// shown on its puzzle page.
+func (c *client) completion(year, day int) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d/day/%d", c.baseURL, year, day), nil)
+	if err != nil {
+		return nil, err
+	}
+	page, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var answers []string
+	for _, m := range solvedAnswer.FindAllStringSubmatch(page, -1) {
+		answers = append(answers, html.UnescapeString(m[1]))
+	}
+	return answers, nil
+}
+
+func (c *client) do(req *http.Request) (string, error) {
+	req.Header.Set("Cookie", "session="+c.session)
+	req.Header.Set("User-Agent", "aoc-in-go (cmd/aoc)")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, oneLine(string(body)))
+	}
+	return string(body), nil
+}
+
+var (
+	article = regexp.MustCompile(`(?s)<article[^>]*>(.*?)</article>`)
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// articleText is the text of the <article> of a page (where the verdict of
+// an answer is), or of the whole page when there is none.
+func articleText(page string) string {
+	if m := article.FindStringSubmatch(page); m != nil {
+		page = m[1]
+	}
+	return oneLine(html.UnescapeString(htmlTag.ReplaceAllString(page, "")))
+}
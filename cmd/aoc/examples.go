@@ -0,0 +1,166 @@
+package main
+
+import (
+	"aoc-in-go/harness"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func examples(args []string) error {
+	fs := flag.NewFlagSet("examples", flag.ExitOnError)
+	force := fs.Bool("force", false, "replace the existing example inputs and answers")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: aoc examples [flags] [year [day]]")
+		fmt.Fprintln(fs.Output(), "Extracts input-example.txt and answers-example.txt from the README.md of each day.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	year, err := yearArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	var dirs []string
+	if fs.NArg() > 1 {
+		day, err := strconv.Atoi(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("invalid day %q", fs.Arg(1))
+		}
+		dirs = []string{dayDir(year, day)}
+	} else if dirs, err = filepath.Glob(filepath.Join(strconv.Itoa(year), "[0-9][0-9]")); err != nil {
+		return err
+	}
+	found := false
+	for _, dir := range dirs {
+		readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		found = true
+		if err := writeExamples(dir, extractExamples(string(readme)), *force); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.New("no README.md found, they are downloaded by ./run.sh")
+	}
+	return nil
+}
+
+// puzzleExamples are the examples of the puzzle text, empty when not found.
+type puzzleExamples struct {
+	inputs  [2]string // part 2 only when it has its own example
+	answers harness.Answers
+}
+
+var (
+	partTwoHeading = regexp.MustCompile(`(?m)^#+ .*Part Two`)
+	codeBlock      = regexp.MustCompile("(?ms)^```[^\n]*\n(.*?)^```")
+	// `_42_`, _`42`_, `**42**` or **`42`**
+	emphasizedCode = regexp.MustCompile("`_([^`_]+)_`|_`([^`]+)`_|`\\*\\*([^`*]+)\\*\\*`|\\*\\*`([^`]+)`\\*\\*")
+	inlineCode     = regexp.MustCompile("`([^`\n]+)`")
+	// the paragraph right before a code block, as matched by puzzler
+	exampleIntro = regexp.MustCompile(`(?i)(for|here is an|here's an) example[^\n]*:\s*$`)
+)
+
+// extractExamples reads the README.md written by puzzler: the example of a
+// part is the first code block of its section with several lines (or the
+// largest one), and its answer is the last emphasized code span. The
+// converted puzzle text usually loses the emphasis inside code spans, the
+// answer is then the last code span before the question ending the section
+// ("... the password in this example is `3`.").
+func extractExamples(readme string) puzzleExamples {
+	sections := []string{readme}
+	if loc := partTwoHeading.FindStringIndex(readme); loc != nil {
+		sections = []string{readme[:loc[0]], readme[loc[0]:]}
+	}
+	e := puzzleExamples{answers: harness.Answers{}}
+	for i, section := range sections {
+		e.inputs[i] = exampleInput(section, i == 1)
+		if answer := exampleAnswer(section); answer != "" {
+			e.answers[strconv.Itoa(i+1)] = answer
+		}
+	}
+	return e
+}
+
+// exampleInput returns the first code block with several lines, or the
+// largest one. Part 2 blocks are usually steps of the part 1 example, they
+// are only taken when introduced as an example ("For example ...:").
+func exampleInput(section string, introduced bool) string {
+	largest := ""
+	for _, loc := range codeBlock.FindAllStringSubmatchIndex(section, -1) {
+		if introduced && !exampleIntro.MatchString(section[:loc[0]]) {
+			continue
+		}
+		block := strings.Trim(section[loc[2]:loc[3]], "\n")
+		if strings.Contains(block, "\n") {
+			return block
+		}
+		if len(block) > len(largest) {
+			largest = block
+		}
+	}
+	return largest
+}
+
+func exampleAnswer(section string) string {
+	// code spans in code blocks are not answers
+	text := codeBlock.ReplaceAllString(section, "")
+	if all := emphasizedCode.FindAllStringSubmatch(text, -1); len(all) > 0 {
+		for _, group := range all[len(all)-1][1:] {
+			if group != "" {
+				return group
+			}
+		}
+	}
+	paragraphs := strings.Split(strings.TrimSpace(text), "\n\n")
+	// the question, then the closest paragraph with code spans before it
+	question := len(paragraphs) - 1
+	for question > 0 && !strings.Contains(paragraphs[question], "?") {
+		question--
+	}
+	for i := question - 1; i >= 0; i-- {
+		if spans := inlineCode.FindAllStringSubmatch(paragraphs[i], -1); len(spans) > 0 {
+			return spans[len(spans)-1][1]
+		}
+	}
+	return ""
+}
+
+// writeExamples writes input-example.txt, input-example2.txt when part 2 has
+// its own example, and answers-example.txt, keeping the existing files
+// unless forced.
+func writeExamples(dir string, e puzzleExamples, force bool) error {
+	write := func(name, content string) error {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Printf("%s: kept, use -force to replace it\n", path)
+			return nil
+		}
+		fmt.Printf("%s: written\n", path)
+		return os.WriteFile(path, []byte(content), 0o644)
+	}
+	if e.inputs[0] == "" {
+		fmt.Printf("%s: no example found in README.md\n", dir)
+	} else if err := write("input-example.txt", e.inputs[0]+"\n"); err != nil {
+		return err
+	}
+	if e.inputs[1] != "" && e.inputs[1] != e.inputs[0] {
+		if err := write("input-example2.txt", e.inputs[1]+"\n"); err != nil {
+			return err
+		}
+	}
+	if len(e.answers) == 0 {
+		fmt.Printf("%s: no example answer found in README.md\n", dir)
+		return nil
+	}
+	return write(harness.AnswersFile("example"), e.answers.String())
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"aoc-in-go/harness"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// readme joins paragraphs as in the README.md written by puzzler, from the
+// <article> of each part converted to markdown.
+func readme(paragraphs ...string) string {
+	return strings.Join(paragraphs, "\n\n")
+}
+
+const (
+	part1Heading = `## \-\-\- Day 1: Secret Entrance ---`
+	part2Heading = `## \-\-\- Part Two ---`
+	// blocks end with a blank line, as converted from <pre><code>
+	rotations = "```\nL68\nL30\nR48\n\n```"
+)
+
+func TestExtractExamples(t *testing.T) {
+	part1 := readme(
+		part1Heading,
+		"The dial starts by pointing at `50`.",
+		"For example, suppose the attached document contained the following rotations:",
+		rotations,
+		"Following these rotations would cause the dial to move as follows:",
+		"- The dial starts by pointing at `50`.\n- The dial is rotated `L68` to point at `82`.",
+		// <code><em>3</em></code> loses its emphasis
+		"Because the dial points at `0` a total of three times during this process, the password in this example is `3`.",
+		"Analyze the rotations in your attached document. _What's the actual password to open the door?_",
+	)
+	tests := []struct {
+		name    string
+		readme  string
+		inputs  [2]string
+		answers harness.Answers
+	}{
+		{
+			"no part 2",
+			part1,
+			[2]string{"L68\nL30\nR48"},
+			harness.Answers{"1": "3"},
+		},
+		{
+			"part 2 on the part 1 example",
+			readme(
+				part1,
+				part2Heading,
+				"Following the same rotations as in the above example, the dial points at zero a few extra times during its rotations:",
+				// a step of the example, not introduced as one
+				"```\nL68\n\n```",
+				"In this example, the dial points at `0` three times at the end of a rotation, plus three more times during a rotation. So, in this example, the new password would be _`6`_.",
+				"_Using password method `0x434C49434B`, what is the password to open the door?_",
+			),
+			[2]string{"L68\nL30\nR48"},
+			harness.Answers{"1": "3", "2": "6"},
+		},
+		{
+			"part 2 with its own example",
+			readme(
+				part1,
+				part2Heading,
+				"For example:",
+				"```\nR1000\nL5\n\n```",
+				"This would make the dial point at `0` `**11**` times.",
+				"_What is the password?_",
+			),
+			[2]string{"L68\nL30\nR48", "R1000\nL5"},
+			harness.Answers{"1": "3", "2": "11"},
+		},
+		{
+			"emphasized answers",
+			readme(
+				"## \\-\\-\\- Day 2: Gift Shop ---",
+				"For example:",
+				"```\n11-22,95-115\n\n```",
+				"Adding up all the invalid IDs in this example produces `_1227775554_`.",
+				"_What do you get if you add up all of the invalid IDs?_",
+				part2Heading,
+				"The sum is now **`4174379265`**, not `1227775554`.",
+				"_What do you get?_",
+			),
+			[2]string{"11-22,95-115"},
+			harness.Answers{"1": "1227775554", "2": "4174379265"},
+		},
+		{
+			"single line example",
+			readme(
+				"## \\-\\-\\- Day 3: Lobby ---",
+				"The batteries `12` and `34` are short:",
+				"```\n12\n\n```",
+				"For example:",
+				"```\n987654321111111\n\n```",
+				// code spans of the blocks are not answers
+				"```\n`98`\n\n```",
+				"The total output joltage is `98`.",
+				"_What is the total output joltage?_",
+			),
+			[2]string{"987654321111111"},
+			harness.Answers{"1": "98"},
+		},
+		{
+			"nothing found",
+			readme("## \\-\\-\\- Day 4 ---", "The rolls are everywhere.", "_How many?_"),
+			[2]string{},
+			harness.Answers{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := extractExamples(test.readme)
+			if e.inputs != test.inputs {
+				t.Errorf("inputs = %q, want %q", e.inputs, test.inputs)
+			}
+			if !maps.Equal(e.answers, test.answers) {
+				t.Errorf("answers = %v, want %v", e.answers, test.answers)
+			}
+		})
+	}
+}
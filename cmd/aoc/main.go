@@ -0,0 +1,72 @@
+// Command aoc gathers the tasks which span several days, next to run.sh
+// which works on one day:
+//
+//	go run ./cmd/aoc run-all [flags] [year]
+//	go run ./cmd/aoc examples [flags] [year [day]]
+//	go run ./cmd/aoc submit <year> <day> <part> [answer]
+//	go run ./cmd/aoc stub [flags] <fixtures dir>
+//
+// Run `go run ./cmd/aoc <command> -h` for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+type command struct {
+	name, usage string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{"run-all", "run every day of a year on the user input and check the answers", runAll},
+	{"examples", "extract the example inputs and answers from the README.md of the days", examples},
+	{"submit", "submit an answer with AOC_SESSION, logging the guesses of each day", submit},
+	{"stub", "serve puzzles from fixtures as adventofcode.com, for AOC_BASE_URL", stub},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	i := slices.IndexFunc(commands, func(c command) bool { return c.name == os.Args[1] })
+	if i == -1 {
+		fmt.Fprintf(os.Stderr, "aoc: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := commands[i].run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "aoc %s: %s\n", commands[i].name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aoc <command> [flags] [args]")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+}
+
+// latestYear returns the most recent <year> directory, run from the
+// repository root.
+func latestYear() (int, error) {
+	dirs, err := filepath.Glob("[0-9][0-9][0-9][0-9]")
+	if err != nil {
+		return 0, err
+	}
+	if len(dirs) == 0 {
+		return 0, fmt.Errorf("no <year> directory, run from the repository root")
+	}
+	return strconv.Atoi(slices.Max(dirs))
+}
+
+// dayDir returns the directory of a day, such as 2025/01.
+func dayDir(year, day int) string {
+	return filepath.Join(strconv.Itoa(year), fmt.Sprintf("%02d", day))
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"aoc-in-go/harness"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// partRow is a line of the run-all table.
+type partRow struct {
+	Day      int           `json:"day"`
+	Part     string        `json:"part"`
+	Answer   string        `json:"answer"`
+	Expected string        `json:"expected,omitempty"`
+	Status   string        `json:"status"` // pass, fail, new (no recorded answer), error, failed, panicked, timed out
+	Duration time.Duration `json:"duration"`
+}
+
+type yearReport struct {
+	Year  int           `json:"year"`
+	Rows  []partRow     `json:"rows"`
+	Total time.Duration `json:"total"` // sum of the run times
+	Wall  time.Duration `json:"wall"`  // including go run builds, with parallel days
+}
+
+func runAll(args []string) error {
+	fs := flag.NewFlagSet("run-all", flag.ExitOnError)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of days run in parallel, 1 for accurate timings")
+	format := fs.String("format", "table", "output format: table, markdown or json")
+	timeout := fs.Duration("timeout", 0, "deadline of each part run, none when 0")
+	record := fs.Bool("record", false, "record the answers of the parts without one in answers-user.txt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: aoc run-all [flags] [year]")
+		fmt.Fprintln(fs.Output(), "Runs every <year>/<day>/code.go on input-user.txt, checking the answers recorded in answers-user.txt.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "table" && *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	year, err := yearArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	days, err := filepath.Glob(filepath.Join(strconv.Itoa(year), "[0-9][0-9]", "code.go"))
+	if err != nil {
+		return err
+	}
+	if len(days) == 0 {
+		return fmt.Errorf("no day in %d", year)
+	}
+
+	ts := time.Now()
+	rows := make([][]partRow, len(days))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(1, *workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				day, _ := strconv.Atoi(filepath.Base(filepath.Dir(days[i])))
+				rows[i] = runDay(year, day, *timeout, *record)
+			}
+		}()
+	}
+	for i := range days {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := yearReport{Year: year, Wall: time.Since(ts)}
+	failed := false
+	for _, dayRows := range rows {
+		for _, row := range dayRows {
+			report.Rows = append(report.Rows, row)
+			report.Total += row.Duration
+			failed = failed || row.Status != "pass" && row.Status != "new"
+		}
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "markdown":
+		writeMarkdown(os.Stdout, report)
+	default:
+		writeTable(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+	if failed {
+		return errors.New("some parts did not pass")
+	}
+	return nil
+}
+
+func yearArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return latestYear()
+	}
+	year, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid year %q", args[0])
+	}
+	return year, nil
+}
+
+// runDay runs both parts of a day on the user input, the way run.sh does,
+// reading the results from the AOC_RESULTS file of the harness.
+func runDay(year, day int, timeout time.Duration, record bool) []partRow {
+	dir := dayDir(year, day)
+	results, err := os.CreateTemp("", "aoc-results-*.jsonl")
+	if err != nil {
+		return []partRow{{Day: day, Status: "error", Answer: err.Error()}}
+	}
+	results.Close()
+	defer os.Remove(results.Name())
+
+	cmd := exec.Command("go", "run", "code.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "AOC_HARNESS=1", "INPUT=user", "AOC_PART2=true", "AOC_RESULTS="+results.Name())
+	if timeout > 0 {
+		cmd.Env = append(cmd.Env, "TIMEOUT="+timeout.String())
+	}
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	runErr := cmd.Run()
+
+	expected, err := harness.ReadAnswers(filepath.Join(dir, harness.AnswersFile("user")))
+	if err != nil {
+		return []partRow{{Day: day, Status: "error", Answer: err.Error()}}
+	}
+	var rows []partRow
+	f, err := os.Open(results.Name())
+	if err != nil {
+		return []partRow{{Day: day, Status: "error", Answer: err.Error()}}
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r harness.Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		row := partRow{Day: day, Part: r.Part, Answer: r.Value, Expected: expected[r.Part], Duration: r.Duration}
+		switch {
+		case r.Status != "returned":
+			row.Status = r.Status
+		case row.Expected == "":
+			row.Status = "new"
+		case row.Answer == row.Expected:
+			row.Status = "pass"
+		default:
+			row.Status = "fail"
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 && runErr != nil {
+		// the day did not build, or crashed before any run
+		return []partRow{{Day: day, Status: "error", Answer: errorLine(output.String())}}
+	}
+	if record {
+		recordAnswers(dir, expected, rows)
+	}
+	return rows
+}
+
+// recordAnswers adds the answers of the parts which had none, never
+// replacing a recorded one.
+func recordAnswers(dir string, answers harness.Answers, rows []partRow) {
+	changed := false
+	for _, row := range rows {
+		if row.Status == "new" {
+			answers[row.Part] = row.Answer
+			changed = true
+		}
+	}
+	if changed {
+		if err := harness.WriteAnswers(filepath.Join(dir, harness.AnswersFile("user")), answers); err != nil {
+			fmt.Fprintf(os.Stderr, "aoc run-all: %s\n", err)
+		}
+	}
+}
+
+// errorLine picks the compiler error in the code of the day, or the first
+// line of the output.
+func errorLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "code.go:") {
+			return line
+		}
+	}
+	return lines[0]
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeTable(w io.Writer, report yearReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tPART\tANSWER\tSTATUS\tTIME")
+	for _, row := range report.Rows {
+		answer := oneLine(row.Answer)
+		if runes := []rune(answer); len(runes) > 60 {
+			// long errors are in the json format
+			answer = string(runes[:59]) + "…"
+		}
+		fmt.Fprintf(tw, "%02d\t%s\t%s\t%s\t%s\n", row.Day, row.Part, answer, rowStatus(row), row.Duration.Round(time.Microsecond))
+	}
+	fmt.Fprintf(tw, "\t\t\ttotal\t%s\n", report.Total.Round(time.Microsecond))
+	tw.Flush()
+	fmt.Fprintf(w, "%d %s, %s wall time\n", len(report.Rows), plural(len(report.Rows), "part"), report.Wall.Round(time.Millisecond))
+}
+
+func writeMarkdown(w io.Writer, report yearReport) {
+	fmt.Fprintf(w, "## %d\n\n", report.Year)
+	fmt.Fprintln(w, "| Day | Part | Answer | Status | Time |")
+	fmt.Fprintln(w, "|----:|-----:|-------:|--------|-----:|")
+	for _, row := range report.Rows {
+		answer := strings.ReplaceAll(oneLine(row.Answer), "|", `\|`)
+		if row.Status != "error" {
+			answer = "`" + answer + "`"
+		}
+		fmt.Fprintf(w, "| %d | %s | %s | %s | %s |\n", row.Day, row.Part, answer, rowStatus(row), row.Duration.Round(time.Microsecond))
+	}
+	fmt.Fprintf(w, "| | | | **total** | **%s** |\n", report.Total.Round(time.Microsecond))
+}
+
+// rowStatus shows the expected answer of failed parts.
+func rowStatus(row partRow) string {
+	if row.Status == "fail" {
+		return "fail (expected " + row.Expected + ")"
+	}
+	return row.Status
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
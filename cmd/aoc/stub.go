@@ -0,0 +1,57 @@
+package main
+
+import (
+	"aoc-in-go/aocstub"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+func stub(args []string) error {
+	fs := flag.NewFlagSet("stub", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8911", "listen address")
+	session := fs.String("session", "", "session cookie accepted, any when empty")
+	cooldown := fs.Duration("cooldown", 0, "wait after a wrong answer, none when 0")
+	rpm := fs.Int("rpm", 0, "requests per minute above which 429 is replied, no limit when 0")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: aoc stub [flags] <fixtures dir>")
+		fmt.Fprintln(fs.Output(), "Serves the puzzles of <dir>/<year>/<day>/{part1.html,part2.html,input.txt,answers.txt} as adventofcode.com.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	puzzles, err := aocstub.LoadPuzzles(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	s := aocstub.NewUnstarted(puzzles...)
+	s.Session, s.Cooldown, s.RequestsPerMinute = *session, *cooldown, *rpm
+	s.Listener.Close()
+	s.Listener = l
+	next := s.Config.Handler
+	s.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+	s.Start()
+	defer s.Close()
+	fmt.Printf("serving %d puzzles, export AOC_BASE_URL=%s\n", len(puzzles), s.URL)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	for _, sub := range s.Submissions() {
+		fmt.Printf("%d/%02d part %d: %s %s\n", sub.Year, sub.Day, sub.Part, sub.Answer, sub.Verdict)
+	}
+	return nil
+}
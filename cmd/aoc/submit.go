@@ -0,0 +1,282 @@
+package main
+
+import (
+	"aoc-in-go/harness"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// guess is a submitted answer, as logged in <year>/<day>/guesses.jsonl.
+type guess struct {
+	Time    time.Time     `json:"time"`
+	Part    string        `json:"part"`
+	Answer  string        `json:"answer"`
+	Verdict string        `json:"verdict"`        // correct, too high, too low, wrong, cooldown or wrong level
+	Wait    time.Duration `json:"wait,omitempty"` // before the server takes another answer
+	Message string        `json:"message"`
+}
+
+const guessesFile = "guesses.jsonl"
+
+func submit(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: aoc submit <year> <day> <part> [answer]")
+		fmt.Fprintln(fs.Output(), "Submits an answer with AOC_SESSION and logs the verdict in <year>/<day>/guesses.jsonl.")
+		fmt.Fprintln(fs.Output(), "Answers known to be wrong or out of the bounds of the previous guesses are refused.")
+		fmt.Fprintln(fs.Output(), "Without an answer, prints the guesses of the part.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 3 || fs.NArg() > 4 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	year, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid year %q", fs.Arg(0))
+	}
+	day, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid day %q", fs.Arg(1))
+	}
+	part := fs.Arg(2)
+	if part != "1" && part != "2" {
+		return fmt.Errorf("invalid part %q, expected 1 or 2", part)
+	}
+	dir := dayDir(year, day)
+	guesses, err := readGuesses(filepath.Join(dir, guessesFile))
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 3 {
+		printGuesses(guesses, part)
+		return nil
+	}
+	answer := strings.TrimSpace(fs.Arg(3))
+	if answer == "" {
+		return errors.New("empty answer")
+	}
+	if err := checkGuess(guesses, part, answer, time.Now()); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	// solved elsewhere, e.g. on the website
+	solved, err := c.completion(year, day)
+	if err != nil {
+		return err
+	}
+	if n, _ := strconv.Atoi(part); len(solved) >= n {
+		if err := recordCorrect(dir, part, solved[n-1]); err != nil {
+			return err
+		}
+		return fmt.Errorf("part %s is already solved, the answer is %s", part, solved[n-1])
+	}
+	text, err := c.submit(year, day, part, answer)
+	if err != nil {
+		return err
+	}
+	g, err := parseVerdict(text)
+	if err != nil {
+		return err
+	}
+	g.Time, g.Part, g.Answer = time.Now().UTC(), part, answer
+	if err := appendGuess(filepath.Join(dir, guessesFile), g); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", g.Verdict, g.Message)
+	if g.Verdict == "correct" {
+		return recordCorrect(dir, part, answer)
+	}
+	if low, high := bounds(append(guesses, g), part); low != nil || high != nil {
+		fmt.Printf("the answer is %s\n", boundsString(low, high))
+	}
+	return nil
+}
+
+var (
+	// "You have 1m 35s left to wait."
+	cooldownWait = regexp.MustCompile(`You have (?:(\d+)m )?(\d+)s left to wait`)
+	// "please wait one minute before trying again", "wait 5 minutes", and
+	// "wait 30 seconds" from a stub server with a short cooldown
+	wrongWait = regexp.MustCompile(`wait (one|\d+) (minute|second)s? before trying again`)
+)
+
+// parseVerdict reads the verdict of the text of an answer page.
+func parseVerdict(text string) (guess, error) {
+	g := guess{Message: text}
+	switch {
+	case strings.Contains(text, "That's the right answer"):
+		g.Verdict = "correct"
+	case strings.Contains(text, "That's not the right answer"):
+		g.Verdict = "wrong"
+		if strings.Contains(text, "your answer is too high") {
+			g.Verdict = "too high"
+		} else if strings.Contains(text, "your answer is too low") {
+			g.Verdict = "too low"
+		}
+		if m := wrongWait.FindStringSubmatch(text); m != nil {
+			n := 1
+			if m[1] != "one" {
+				n, _ = strconv.Atoi(m[1])
+			}
+			unit := time.Minute
+			if m[2] == "second" {
+				unit = time.Second
+			}
+			g.Wait = time.Duration(n) * unit
+		}
+	case strings.Contains(text, "You gave an answer too recently"):
+		g.Verdict = "cooldown"
+		if m := cooldownWait.FindStringSubmatch(text); m != nil {
+			minutes, _ := strconv.Atoi(m[1])
+			seconds, _ := strconv.Atoi(m[2])
+			g.Wait = time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+		}
+	case strings.Contains(text, "You don't seem to be solving the right level"):
+		// already solved, or part 1 is not
+		g.Verdict = "wrong level"
+	default:
+		return g, fmt.Errorf("unexpected answer page: %s", text)
+	}
+	return g, nil
+}
+
+// checkGuess refuses the answers which are known to be wrong, and those sent
+// before the server takes them again.
+func checkGuess(guesses []guess, part, answer string, now time.Time) error {
+	if len(guesses) > 0 {
+		last := guesses[len(guesses)-1]
+		if until := last.Time.Add(last.Wait); now.Before(until) {
+			return fmt.Errorf("the server takes no answer for %s, until %s", until.Sub(now).Round(time.Second), until.Local().Format(time.TimeOnly))
+		}
+	}
+	for _, g := range guesses {
+		switch {
+		case g.Part != part:
+		case g.Verdict == "correct":
+			return fmt.Errorf("part %s is already solved, the answer is %s", part, g.Answer)
+		case g.Answer == answer && g.Verdict != "cooldown" && g.Verdict != "wrong level":
+			return fmt.Errorf("%s was already submitted on %s: %s", answer, g.Time.Local().Format(time.DateTime), g.Verdict)
+		}
+	}
+	n, ok := new(big.Int).SetString(answer, 10)
+	if !ok {
+		return nil
+	}
+	low, high := bounds(guesses, part)
+	if low != nil && n.Cmp(low) <= 0 || high != nil && n.Cmp(high) >= 0 {
+		return fmt.Errorf("%s is out of bounds, the answer is %s", answer, boundsString(low, high))
+	}
+	return nil
+}
+
+// bounds returns the highest answer too low and the lowest answer too high
+// of a part, nil when there is none.
+func bounds(guesses []guess, part string) (low, high *big.Int) {
+	for _, g := range guesses {
+		if g.Part != part {
+			continue
+		}
+		n, ok := new(big.Int).SetString(g.Answer, 10)
+		switch {
+		case !ok:
+		case g.Verdict == "too low" && (low == nil || n.Cmp(low) > 0):
+			low = n
+		case g.Verdict == "too high" && (high == nil || n.Cmp(high) < 0):
+			high = n
+		}
+	}
+	return low, high
+}
+
+func boundsString(low, high *big.Int) string {
+	switch {
+	case low == nil:
+		return fmt.Sprintf("below %s", high)
+	case high == nil:
+		return fmt.Sprintf("above %s", low)
+	}
+	return fmt.Sprintf("between %s and %s", low, high)
+}
+
+func printGuesses(guesses []guess, part string) {
+	found, solved := false, false
+	for _, g := range guesses {
+		if g.Part == part {
+			found, solved = true, solved || g.Verdict == "correct"
+			fmt.Printf("%s  %-20s %s\n", g.Time.Local().Format(time.DateTime), g.Answer, g.Verdict)
+		}
+	}
+	if !found {
+		fmt.Printf("no guess for part %s\n", part)
+	} else if low, high := bounds(guesses, part); !solved && (low != nil || high != nil) {
+		fmt.Printf("the answer is %s\n", boundsString(low, high))
+	}
+}
+
+// recordCorrect records the answer in answers-user.txt, checked by the
+// harness and run-all from then on.
+func recordCorrect(dir, part, answer string) error {
+	path := filepath.Join(dir, harness.AnswersFile("user"))
+	answers, err := harness.ReadAnswers(path)
+	if err != nil {
+		return err
+	}
+	if answers[part] == answer {
+		return nil
+	}
+	answers[part] = answer
+	return harness.WriteAnswers(path, answers)
+}
+
+// readGuesses reads a guess log, empty when it does not exist.
+func readGuesses(path string) ([]guess, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var guesses []guess
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var g guess
+		if err := json.Unmarshal(scanner.Bytes(), &g); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+		guesses = append(guesses, g)
+	}
+	return guesses, scanner.Err()
+}
+
+func appendGuess(path string, g guess) error {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"aoc-in-go/aocstub"
+	"aoc-in-go/harness"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// answer pages as returned by adventofcode.com
+func answerPage(message string) string {
+	return `<!DOCTYPE html>
+<html lang="en-us"><head><title>Day 1 - Advent of Code 2025</title></head>
+<body><header><h1 class="title-global"><a href="/">Advent of Code</a></h1></header>
+<main>
+<article><p>` + message + `</p></article>
+</main></body></html>`
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		verdict string
+		wait    time.Duration
+	}{
+		{
+			"right",
+			answerPage(`That's the right answer!  You are <span class="day-success">one gold star</span> closer to decorating the North Pole. <a href="/2025/day/1#part2">[Continue to Part Two]</a>`),
+			"correct", 0,
+		},
+		{
+			"too high",
+			answerPage(`That's not the right answer; your answer is too high.  If you're stuck, make sure you're using the full input data; there are also some general tips on the <a href="/2025/about">about page</a>, or you can ask for hints on the <a href="https://www.reddit.com/r/adventofcode/" target="_blank">subreddit</a>.  Please wait one minute before trying again. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"too high", time.Minute,
+		},
+		{
+			"too low",
+			answerPage(`That's not the right answer; your answer is too low.  If you're stuck, make sure you're using the full input data; there are also some general tips on the <a href="/2025/about">about page</a>, or you can ask for hints on the <a href="https://www.reddit.com/r/adventofcode/" target="_blank">subreddit</a>.  Because you have guessed incorrectly 4 times on this puzzle, please wait 5 minutes before trying again. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"too low", 5 * time.Minute,
+		},
+		{
+			"wrong",
+			answerPage(`That's not the right answer.  If you're stuck, make sure you're using the full input data; there are also some general tips on the <a href="/2025/about">about page</a>, or you can ask for hints on the <a href="https://www.reddit.com/r/adventofcode/" target="_blank">subreddit</a>.  Please wait one minute before trying again. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"wrong", time.Minute,
+		},
+		{
+			"wrong, stub waits in seconds",
+			answerPage(`That's not the right answer.  Please wait 30 seconds before trying again. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"wrong", 30 * time.Second,
+		},
+		{
+			"wrong, stub waits one second",
+			answerPage(`That's not the right answer.  Please wait one second before trying again. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"wrong", time.Second,
+		},
+		{
+			"cooldown",
+			answerPage(`You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 34s left to wait. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"cooldown", 34 * time.Second,
+		},
+		{
+			"cooldown minutes",
+			answerPage(`You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 4m 12s left to wait. <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"cooldown", 4*time.Minute + 12*time.Second,
+		},
+		{
+			"already solved",
+			answerPage(`You don't seem to be solving the right level.  Did you already complete it? <a href="/2025/day/1">[Return to Day 1]</a>`),
+			"wrong level", 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := parseVerdict(articleText(test.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.Verdict != test.verdict || g.Wait != test.wait {
+				t.Errorf("parseVerdict = %q, wait %s, want %q, wait %s", g.Verdict, g.Wait, test.verdict, test.wait)
+			}
+			if strings.ContainsAny(g.Message, "<>") {
+				t.Errorf("message %q has HTML left", g.Message)
+			}
+		})
+	}
+	if _, err := parseVerdict(articleText(answerPage("To play, please identify yourself via one of these services:"))); err == nil {
+		t.Error("parseVerdict of a login page: no error")
+	}
+}
+
+func TestCheckGuess(t *testing.T) {
+	now := time.Date(2025, 12, 1, 6, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	guesses := []guess{
+		{Time: earlier, Part: "1", Answer: "100", Verdict: "too low", Wait: time.Minute},
+		{Time: earlier, Part: "1", Answer: "500", Verdict: "too high", Wait: time.Minute},
+		{Time: earlier, Part: "1", Answer: "300", Verdict: "wrong", Wait: time.Minute},
+		{Time: earlier, Part: "1", Answer: "200", Verdict: "too low", Wait: time.Minute},
+		{Time: earlier, Part: "1", Answer: "250", Verdict: "cooldown", Wait: 30 * time.Second},
+		{Time: earlier, Part: "2", Answer: "7", Verdict: "too high", Wait: time.Minute},
+	}
+	tests := []struct {
+		part, answer string
+		err          string // part of the error, "" when accepted
+	}{
+		{"1", "201", ""},
+		{"1", "499", ""},
+		{"1", "not a number", ""},
+		// sent during a cooldown, so never judged
+		{"1", "250", ""},
+		{"1", "300", "already submitted"},
+		{"1", "100", "already submitted"},
+		{"1", "150", "out of bounds, the answer is between 200 and 500"},
+		{"1", "200", "already submitted"},
+		{"1", "199", "out of bounds"},
+		{"1", "500", "already submitted"},
+		{"1", "501", "out of bounds"},
+		{"1", "123456789012345678901234567890", "out of bounds"},
+		// the bounds are per part
+		{"2", "150", "out of bounds, the answer is below 7"},
+		{"2", "6", ""},
+	}
+	for _, test := range tests {
+		err := checkGuess(guesses, test.part, test.answer, now)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("checkGuess(part %s, %s) = %q, want no error", test.part, test.answer, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("checkGuess(part %s, %s) = %v, want %q", test.part, test.answer, err, test.err)
+		}
+	}
+
+	// the wait of the last guess, whatever its part
+	waiting := append(guesses, guess{Time: now.Add(-20 * time.Second), Part: "2", Answer: "5", Verdict: "wrong", Wait: time.Minute})
+	if err := checkGuess(waiting, "1", "201", now); err == nil || !strings.Contains(err.Error(), "takes no answer for 40s") {
+		t.Errorf("checkGuess during a wait = %v", err)
+	}
+	if err := checkGuess(waiting, "1", "201", now.Add(40*time.Second)); err != nil {
+		t.Errorf("checkGuess after the wait = %v", err)
+	}
+
+	solved := append(guesses, guess{Time: earlier, Part: "1", Answer: "321", Verdict: "correct"})
+	if err := checkGuess(solved, "1", "322", now); err == nil || !strings.Contains(err.Error(), "already solved, the answer is 321") {
+		t.Errorf("checkGuess of a solved part = %v", err)
+	}
+}
+
+// newStubDay serves the puzzles from a stub server for the rest of the test,
+// which runs in a temporary directory holding the directory of the first
+// puzzle, returned along with the server.
+func newStubDay(t *testing.T, puzzles ...*aocstub.Puzzle) (*aocstub.Server, string) {
+	t.Helper()
+	stub := aocstub.New(puzzles...)
+	t.Cleanup(stub.Close)
+	stub.Session = "test-session"
+	t.Setenv("AOC_BASE_URL", stub.URL)
+	t.Setenv("AOC_SESSION", "test-session")
+	t.Chdir(t.TempDir())
+	dir := dayDir(puzzles[0].Year, puzzles[0].Day)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return stub, dir
+}
+
+// TestSubmit runs the submit command against the stub server.
+func TestSubmit(t *testing.T) {
+	stub, dir := newStubDay(t, &aocstub.Puzzle{Year: 2025, Day: 1, Part1: "<p>part 1</p>", Part2: "<p>part 2</p>", Answers: [2]string{"1123", "6695"}})
+
+	steps := []struct {
+		part, answer string
+		err          string // part of the error, "" when accepted
+	}{
+		{"1", "1000", ""},
+		{"1", "2000", ""},
+		{"1", "1000", "already submitted"},
+		{"1", "999", "out of bounds"},
+		{"1", "2500", "out of bounds"},
+		{"1", "1123", ""},
+		{"1", "1124", "already solved"},
+		{"2", "6695", ""},
+	}
+	for _, step := range steps {
+		err := submit([]string{"2025", "1", step.part, step.answer})
+		switch {
+		case step.err == "" && err != nil:
+			t.Fatalf("submit part %s %s: %s", step.part, step.answer, err)
+		case step.err != "" && (err == nil || !strings.Contains(err.Error(), step.err)):
+			t.Fatalf("submit part %s %s = %v, want %q", step.part, step.answer, err, step.err)
+		}
+	}
+
+	// the refused answers never reach the server
+	var sent []string
+	for _, s := range stub.Submissions() {
+		sent = append(sent, s.Answer+" "+string(s.Verdict))
+	}
+	want := []string{"1000 too low", "2000 too high", "1123 correct", "6695 correct"}
+	if strings.Join(sent, ", ") != strings.Join(want, ", ") {
+		t.Errorf("submissions = %q, want %q", sent, want)
+	}
+	guesses, err := readGuesses(filepath.Join(dir, guessesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(guesses) != len(want) {
+		t.Errorf("%d guesses logged, want %d", len(guesses), len(want))
+	}
+	answers, err := harness.ReadAnswers(filepath.Join(dir, harness.AnswersFile("user")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answers["1"] != "1123" || answers["2"] != "6695" {
+		t.Errorf("answers-user.txt = %v", answers)
+	}
+}
+
+// TestSubmitCooldown checks the waits asked by the server are kept.
+func TestSubmitCooldown(t *testing.T) {
+	stub, _ := newStubDay(t, &aocstub.Puzzle{Year: 2025, Day: 1, Answers: [2]string{"1123", "6695"}})
+	stub.Cooldown = time.Minute
+	if err := submit([]string{"2025", "1", "1", "1000"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := submit([]string{"2025", "1", "1", "1123"}); err == nil || !strings.Contains(err.Error(), "takes no answer") {
+		t.Errorf("submit during the wait = %v", err)
+	}
+	if n := len(stub.Submissions()); n != 1 {
+		t.Errorf("%d submissions, want 1", n)
+	}
+}
+
+// TestSubmitWait checks the waits of the stub server are parsed, whole
+// minutes or not.
+func TestSubmitWait(t *testing.T) {
+	for _, wait := range []time.Duration{time.Second, 30 * time.Second, 90 * time.Second, time.Minute, 5 * time.Minute} {
+		t.Run(wait.String(), func(t *testing.T) {
+			stub, dir := newStubDay(t, &aocstub.Puzzle{Year: 2025, Day: 1, Answers: [2]string{"1123", "6695"}})
+			stub.Cooldown = wait
+			if err := submit([]string{"2025", "1", "1", "1000"}); err != nil {
+				t.Fatal(err)
+			}
+			guesses, err := readGuesses(filepath.Join(dir, guessesFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(guesses) != 1 || guesses[0].Wait != wait {
+				t.Errorf("guesses = %+v, want a wait of %s", guesses, wait)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	newStubDay(t,
+		&aocstub.Puzzle{Year: 2025, Day: 1, Answers: [2]string{"1123", "6695"}},
+		&aocstub.Puzzle{Year: 2025, Day: 2, Answers: [2]string{"<42 & 43>", "6695"}, Solved: 1},
+		&aocstub.Puzzle{Year: 2025, Day: 3, Answers: [2]string{"16993", "168617068915447"}, Solved: 2},
+	)
+	c, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for day, want := range map[int][]string{1: nil, 2: {"<42 & 43>"}, 3: {"16993", "168617068915447"}} {
+		answers, err := c.completion(2025, day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(answers, ", ") != strings.Join(want, ", ") {
+			t.Errorf("completion of day %d = %q, want %q", day, answers, want)
+		}
+	}
+}
+
+// TestSubmitSolved checks a part solved elsewhere is recorded rather than
+// submitted again.
+func TestSubmitSolved(t *testing.T) {
+	stub, dir := newStubDay(t, &aocstub.Puzzle{Year: 2025, Day: 1, Answers: [2]string{"1123", "6695"}, Solved: 1})
+	if err := submit([]string{"2025", "1", "1", "1000"}); err == nil || !strings.Contains(err.Error(), "already solved, the answer is 1123") {
+		t.Errorf("submit of a solved part = %v", err)
+	}
+	if n := len(stub.Submissions()); n != 0 {
+		t.Errorf("%d submissions, want none", n)
+	}
+	answers, err := harness.ReadAnswers(filepath.Join(dir, harness.AnswersFile("user")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answers["1"] != "1123" {
+		t.Errorf("answers-user.txt = %v", answers)
+	}
+	if err := submit([]string{"2025", "1", "2", "6695"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package digitpattern finds the numbers whose digits are a block repeated
+// several times (1212, 777, 123123123...), as the invalid IDs of day 2.
+//
+// The numbers are never scanned one by one: a number of length digits made
+// of a d digits block repeated length/d times is block × repunit, repunit
+// being 1 followed by (d-1 zeros then 1) once per extra repeat (123123 is
+// 123 × 1001). For a given length and d, the blocks giving a number in a
+// range are a contiguous range of integers, which can be counted, summed as
+// an arithmetic series or listed directly.
+package digitpattern
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"math/big"
+)
+
+// Query selects the numbers made of a block repeated between MinRepeats and
+// MaxRepeats times, written in Base. Blocks have no leading zero, so 0 never
+// matches.
+type Query struct {
+	Base       int // 10 when zero
+	MinRepeats int // 2 when zero
+	MaxRepeats int // no limit when zero
+}
+
+// Exactly selects the numbers made of a block repeated n times.
+func Exactly(n int) Query {
+	return Query{MinRepeats: n, MaxRepeats: n}
+}
+
+// AtLeast selects the numbers made of a block repeated n times or more.
+func AtLeast(n int) Query {
+	return Query{MinRepeats: n}
+}
+
+// InBase returns the query for numbers written in the given base.
+func (q Query) InBase(base int) Query {
+	q.Base = base
+	return q
+}
+
+func (q Query) base() uint64 {
+	switch {
+	case q.Base == 0:
+		return 10
+	case q.Base < 2:
+		panic(fmt.Sprintf("digitpattern: invalid base %d", q.Base))
+	}
+	return uint64(q.Base)
+}
+
+// matches tells whether a number made of length digits can be read as a
+// block of d digits repeated an accepted number of times.
+func (q Query) matches(length, d int) bool {
+	if length%d != 0 {
+		return false
+	}
+	repeats := length / d
+	minRepeats := q.MinRepeats
+	if minRepeats == 0 {
+		minRepeats = 2
+	}
+	return repeats >= minRepeats && (q.MaxRepeats == 0 || repeats <= q.MaxRepeats)
+}
+
+// Count returns how many numbers in [first, last] match the query.
+func (q Query) Count(first, last uint64) *big.Int {
+	return q.aggregate(first, last, func(b blocks) *big.Int {
+		return new(big.Int).SetUint64(b.max - b.min + 1)
+	})
+}
+
+// Sum returns the sum of the numbers in [first, last] matching the query.
+func (q Query) Sum(first, last uint64) *big.Int {
+	return q.aggregate(first, last, func(b blocks) *big.Int {
+		// repunit × (min + max) × count / 2
+		sum := new(big.Int).SetUint64(b.min)
+		sum.Add(sum, new(big.Int).SetUint64(b.max))
+		sum.Mul(sum, new(big.Int).SetUint64(b.max-b.min+1))
+		sum.Rsh(sum, 1)
+		return sum.Mul(sum, new(big.Int).SetUint64(b.repunit))
+	})
+}
+
+// aggregate adds up the count or sum of the matching numbers of each length.
+// A number usually has several readings (1111 is "11"×2 and "1"×4), so the
+// numbers are split by their smallest block, their period p, and counted
+// once when one of the block sizes multiple of p is accepted. The numbers of
+// period exactly p are the ones having a p digits block minus the ones with
+// a smaller period dividing p (inclusion–exclusion over the divisors).
+func (q Query) aggregate(first, last uint64, of func(blocks) *big.Int) *big.Int {
+	base := q.base()
+	total := new(big.Int)
+	if first > last {
+		return total
+	}
+	for length := digitCount(first, base); length <= digitCount(last, base); length++ {
+		exact := map[int]*big.Int{}
+		for p := 1; p <= length; p++ {
+			if length%p != 0 {
+				continue
+			}
+			b, ok := blockRange(first, last, length, p, base)
+			if !ok {
+				exact[p] = new(big.Int)
+				continue
+			}
+			periodic := of(b)
+			for e, eAggregate := range exact {
+				if p%e == 0 {
+					periodic.Sub(periodic, eAggregate)
+				}
+			}
+			exact[p] = periodic
+			for d := p; d <= length; d += p {
+				if q.matches(length, d) {
+					total.Add(total, periodic)
+					break
+				}
+			}
+		}
+	}
+	return total
+}
+
+// List iterates in increasing order over the numbers in [first, last]
+// matching the query.
+func (q Query) List(first, last uint64) iter.Seq[uint64] {
+	base := q.base()
+	return func(yield func(uint64) bool) {
+		if first > last {
+			return
+		}
+		for length := digitCount(first, base); length <= digitCount(last, base); length++ {
+			// one increasing sequence per accepted block size, merged
+			var sequences []blocks
+			for d := 1; d <= length; d++ {
+				if !q.matches(length, d) {
+					continue
+				}
+				if b, ok := blockRange(first, last, length, d, base); ok {
+					sequences = append(sequences, b)
+				}
+			}
+			for len(sequences) > 0 {
+				next := uint64(math.MaxUint64)
+				for _, b := range sequences {
+					next = min(next, b.min*b.repunit)
+				}
+				if !yield(next) {
+					return
+				}
+				// advance every sequence at this number, the same number can
+				// come from several block sizes
+				remaining := sequences[:0]
+				for _, b := range sequences {
+					if b.min*b.repunit == next {
+						b.min++
+					}
+					if b.min <= b.max {
+						remaining = append(remaining, b)
+					}
+				}
+				sequences = remaining
+			}
+		}
+	}
+}
+
+// blocks is the range of d digits blocks [min, max] which, repeated, give a
+// number of a given length in the queried range.
+type blocks struct {
+	min, max uint64
+	repunit  uint64
+}
+
+func blockRange(first, last uint64, length, d int, base uint64) (blocks, bool) {
+	repunit := uint64(0)
+	for range length / d {
+		repunit = repunit*pow(base, d) + 1
+	}
+	low := max(first, pow(base, length-1))
+	high := min(last, saturatingSub(pow(base, length), 1))
+	b := blocks{
+		min:     max(ceilDiv(low, repunit), pow(base, d-1)),
+		max:     min(high/repunit, saturatingSub(pow(base, d), 1)),
+		repunit: repunit,
+	}
+	return b, b.min <= b.max
+}
+
+func ceilDiv(a, b uint64) uint64 {
+	if a%b == 0 {
+		return a / b
+	}
+	return a/b + 1
+}
+
+func digitCount(n, base uint64) int {
+	count := 1
+	for n >= base {
+		n /= base
+		count++
+	}
+	return count
+}
+
+// pow saturates at math.MaxUint64, which is past any bound given as uint64.
+func pow(base uint64, n int) uint64 {
+	p := uint64(1)
+	for range n {
+		if p > math.MaxUint64/base {
+			return math.MaxUint64
+		}
+		p *= base
+	}
+	return p
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if a == math.MaxUint64 {
+		return a
+	}
+	return a - b
+}
@@ -0,0 +1,141 @@
+package digitpattern
+
+import (
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// bruteMatches reads n in the base of the query and tries every accepted
+// number of repeats.
+func bruteMatches(q Query, n uint64) bool {
+	digits := strconv.FormatUint(n, int(q.base()))
+	minRepeats, maxRepeats := q.MinRepeats, q.MaxRepeats
+	if minRepeats == 0 {
+		minRepeats = 2
+	}
+	if maxRepeats == 0 {
+		maxRepeats = len(digits)
+	}
+	for k := minRepeats; k <= min(maxRepeats, len(digits)); k++ {
+		if len(digits)%k == 0 && digits == strings.Repeat(digits[:len(digits)/k], k) {
+			return true
+		}
+	}
+	return false
+}
+
+// check compares Count, Sum and List with a scan of [first, last].
+func check(t *testing.T, name string, q Query, first, last uint64) {
+	t.Helper()
+	var want []uint64
+	wantSum := new(big.Int)
+	for n := first; ; n++ {
+		if bruteMatches(q, n) {
+			want = append(want, n)
+			wantSum.Add(wantSum, new(big.Int).SetUint64(n))
+		}
+		if n == last {
+			break
+		}
+	}
+	if got := slices.Collect(q.List(first, last)); !slices.Equal(got, want) {
+		t.Errorf("%s: List(%d, %d) = %v, want %v", name, first, last, got, want)
+	}
+	if got := q.Count(first, last); got.Cmp(big.NewInt(int64(len(want)))) != 0 {
+		t.Errorf("%s: Count(%d, %d) = %s, want %d", name, first, last, got, len(want))
+	}
+	if got := q.Sum(first, last); got.Cmp(wantSum) != 0 {
+		t.Errorf("%s: Sum(%d, %d) = %s, want %s", name, first, last, got, wantSum)
+	}
+}
+
+var queries = []struct {
+	name  string
+	query Query
+}{
+	{"twice", Exactly(2)},
+	{"three times", Exactly(3)},
+	{"at least twice", AtLeast(2)},
+	{"at least 3 times", AtLeast(3)},
+	{"2 or 3 times", Query{MinRepeats: 2, MaxRepeats: 3}},
+	{"at most 4 times", Query{MaxRepeats: 4}},
+	{"zero value", Query{}},
+}
+
+func TestQueries(t *testing.T) {
+	for _, base := range []int{2, 3, 10, 16} {
+		for _, test := range queries {
+			q := test.query.InBase(base)
+			name := test.name + " in base " + strconv.Itoa(base)
+			check(t, name, q, 0, 70000)
+			check(t, name, q, 1000, 1000)
+			check(t, name, q, 4095, 4096)
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	// day 2: 11-22 has 11 and 22, 95-115 has 99, and 111 too once more
+	// than two repeats are accepted
+	if got := slices.Collect(Exactly(2).List(11, 22)); !slices.Equal(got, []uint64{11, 22}) {
+		t.Errorf("Exactly(2).List(11, 22) = %v", got)
+	}
+	if got := slices.Collect(Exactly(2).List(95, 115)); !slices.Equal(got, []uint64{99}) {
+		t.Errorf("Exactly(2).List(95, 115) = %v", got)
+	}
+	if got := slices.Collect(AtLeast(2).List(95, 115)); !slices.Equal(got, []uint64{99, 111}) {
+		t.Errorf("AtLeast(2).List(95, 115) = %v", got)
+	}
+	// 0b101010 is "10" three times
+	if got := slices.Collect(Exactly(3).InBase(2).List(40, 45)); !slices.Equal(got, []uint64{42}) {
+		t.Errorf("Exactly(3).InBase(2).List(40, 45) = %v", got)
+	}
+	if got := Exactly(2).Count(10, 9); got.Sign() != 0 {
+		t.Errorf("Count of an empty range = %s", got)
+	}
+}
+
+// TestBounds checks the ranges ending at MaxUint64, whose digit counts are
+// the largest and where the powers of the base saturate.
+func TestBounds(t *testing.T) {
+	const top = math.MaxUint64
+	for _, base := range []int{2, 3, 10, 16} {
+		for _, test := range queries {
+			q := test.query.InBase(base)
+			check(t, test.name+" in base "+strconv.Itoa(base), q, top-1<<16, top)
+		}
+	}
+	// 1844674407 twice, the largest such number in base 10
+	check(t, "twice near the top", Exactly(2), 18446744071844674407-1000, 18446744071844674407+1000)
+	// 0xffffffffffffffff is f repeated 16 times, 1 repeated 64 times in base 2
+	for base, repeats := range map[int]int{2: 64, 16: 16} {
+		if got := slices.Collect(Exactly(repeats).InBase(base).List(top-1, top)); !slices.Equal(got, []uint64{top}) {
+			t.Errorf("Exactly(%d).InBase(%d).List(top-1, top) = %v", repeats, base, got)
+		}
+	}
+	// every length of number, through the last one
+	count := Exactly(2).Count(0, top)
+	want := new(big.Int)
+	for d := 1; d <= 9; d++ {
+		// 9×10^(d-1) blocks of d digits
+		want.Add(want, new(big.Int).Mul(big.NewInt(9), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d-1)), nil)))
+	}
+	// 10 digits blocks up to 1844674407
+	want.Add(want, big.NewInt(1844674407-1000000000+1))
+	if count.Cmp(want) != 0 {
+		t.Errorf("Exactly(2).Count(0, MaxUint64) = %s, want %s", count, want)
+	}
+}
+
+func TestInvalidBase(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("base 1 did not panic")
+		}
+	}()
+	Exactly(2).InBase(1).Count(1, 10)
+}
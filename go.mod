@@ -2,7 +2,12 @@ module aoc-in-go
 
 go 1.24.2
 
-require github.com/jpillora/puzzler v1.3.3
+require (
+	github.com/google/pprof v0.0.0-20260115054156-294ebfa9ad83
+	github.com/jpillora/ansi v1.0.3
+	github.com/jpillora/puzzler v1.3.3
+	github.com/maruel/panicparse/v2 v2.3.1
+)
 
 require (
 	github.com/JohannesKaufmann/html-to-markdown v1.4.2 // indirect
@@ -11,11 +16,9 @@ require (
 	github.com/andybalholm/cascadia v1.3.2 // indirect
 	github.com/chriso345/gspl v0.0.2 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
-	github.com/jpillora/ansi v1.0.3 // indirect
 	github.com/jpillora/maplock v0.0.0-20160420012925-5c725ac6e22a // indirect
-	github.com/maruel/panicparse/v2 v2.3.1 // indirect
 	golang.org/x/net v0.19.0 // indirect
 	golang.org/x/sync v0.5.0 // indirect
-	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
 	gonum.org/v1/gonum v0.16.0 // indirect
 )
@@ -0,0 +1,144 @@
+// Package graphviz reads and writes the Graphviz DOT format for the puzzle
+// graphs (day 11 device adjacency lists, day 8 junction box forests...), so
+// they can be rendered with `dot -Tsvg` and inspected.
+package graphviz
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Graph is a small ordered graph model: nodes and edges keep their insertion
+// order so the written DOT is stable from one run to the next.
+type Graph struct {
+	Name     string
+	Directed bool
+	Nodes    []Node
+	Edges    []Edge
+
+	index map[string]int
+}
+
+type Node struct {
+	ID    string
+	Label string // optional, defaults to the ID
+}
+
+type Edge struct {
+	From, To string
+	Label    string // optional
+}
+
+func New(name string, directed bool) *Graph {
+	return &Graph{Name: name, Directed: directed}
+}
+
+// AddNode adds the node if missing and returns it for label updates. The
+// pointer is only valid until the next node is added.
+func (g *Graph) AddNode(id string) *Node {
+	if g.index == nil {
+		g.index = make(map[string]int)
+		for i, node := range g.Nodes {
+			g.index[node.ID] = i
+		}
+	}
+	if i, ok := g.index[id]; ok {
+		return &g.Nodes[i]
+	}
+	g.index[id] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, Node{ID: id})
+	return &g.Nodes[len(g.Nodes)-1]
+}
+
+// AddEdge adds an edge, creating both endpoints if needed. The pointer is
+// only valid until the next edge is added.
+func (g *Graph) AddEdge(from, to string) *Edge {
+	g.AddNode(from)
+	g.AddNode(to)
+	g.Edges = append(g.Edges, Edge{From: from, To: to})
+	return &g.Edges[len(g.Edges)-1]
+}
+
+// FromAdjacency builds a directed graph from a map of node -> outputs, with
+// nodes sorted by name since map order is random.
+func FromAdjacency(name string, adjacency map[string][]string) *Graph {
+	g := New(name, true)
+	sources := make([]string, 0, len(adjacency))
+	for source := range adjacency {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	for _, source := range sources {
+		g.AddNode(source)
+		for _, output := range adjacency[source] {
+			g.AddEdge(source, output)
+		}
+	}
+	return g
+}
+
+// ParseAdjacency reads the day 11 format, one node per line: `aaa: bbb ccc`.
+func ParseAdjacency(name, input string) (*Graph, error) {
+	g := New(name, true)
+	for i, line := range strings.Split(strings.TrimSpace(input), "\n") {
+		source, outputs, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing ':' in %q", i+1, line)
+		}
+		g.AddNode(strings.TrimSpace(source))
+		for _, output := range strings.Fields(outputs) {
+			g.AddEdge(strings.TrimSpace(source), output)
+		}
+	}
+	return g, nil
+}
+
+// Adjacency returns the graph as a map of node -> outputs. Undirected edges
+// are added in both directions.
+func (g *Graph) Adjacency() map[string][]string {
+	adjacency := make(map[string][]string, len(g.Nodes))
+	for _, edge := range g.Edges {
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+		if !g.Directed {
+			adjacency[edge.To] = append(adjacency[edge.To], edge.From)
+		}
+	}
+	return adjacency
+}
+
+// OnPaths returns the nodes lying on at least one path from source to
+// destination, that is the nodes of the DAG a path counter actually walks.
+func OnPaths(adjacency map[string][]string, source, destination string) map[string]bool {
+	reversed := make(map[string][]string)
+	for node, outputs := range adjacency {
+		for _, output := range outputs {
+			reversed[output] = append(reversed[output], node)
+		}
+	}
+	forward := reach(adjacency, source)
+	backward := reach(reversed, destination)
+	onPaths := make(map[string]bool)
+	for node := range forward {
+		if backward[node] {
+			onPaths[node] = true
+		}
+	}
+	return onPaths
+}
+
+func reach(adjacency map[string][]string, start string) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[node] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
@@ -0,0 +1,398 @@
+package graphviz
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Parse reads a DOT graph back, so a hand-edited or exported .dot file can be
+// used as puzzle input. It supports the common subset of the language: node
+// and edge statements, edge chains, `{a b}` groups and subgraphs, attribute
+// lists (only `label` is kept), and comments. Ports are ignored and HTML
+// labels are not supported.
+func Parse(input string) (*Graph, error) {
+	p := &parser{lexer: lexer{input: input}}
+	g, err := p.graph()
+	if err != nil {
+		line := 1 + strings.Count(input[:min(p.lexer.pos, len(input))], "\n")
+		return nil, fmt.Errorf("dot: line %d: %w", line, err)
+	}
+	return g, nil
+}
+
+// IsDOT reports whether the input starts as a DOT graph (`strict`, `graph`
+// or `digraph`, case-insensitive, possibly after comments), rather than as a
+// puzzle format.
+func IsDOT(input string) bool {
+	l := lexer{input: input}
+	t, err := l.next()
+	if err != nil || t.kind != tokenID {
+		return false
+	}
+	switch strings.ToLower(t.value) {
+	case "strict", "graph", "digraph":
+		// followed by the graph name, `{` or `graph` after strict
+		t, err = l.next()
+		return err == nil && (t.isID() || t.kind == tokenPunct && t.value == "{")
+	}
+	return false
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenID
+	tokenQuoted // a quoted ID, never a keyword
+	tokenPunct  // { } [ ] ; , = :
+	tokenEdgeOp
+)
+
+func (t token) isID() bool {
+	return t.kind == tokenID || t.kind == tokenQuoted
+}
+
+// isKeyword reports whether t is the unquoted keyword, keywords being
+// case-insensitive.
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenID && strings.EqualFold(t.value, keyword)
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpaceAndComments()
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF}, nil
+	}
+	c := l.input[l.pos]
+	switch {
+	case strings.IndexByte("{}[];,=:", c) >= 0:
+		l.pos++
+		return token{tokenPunct, string(c)}, nil
+	case strings.HasPrefix(l.input[l.pos:], "->"), strings.HasPrefix(l.input[l.pos:], "--"):
+		l.pos += 2
+		return token{tokenEdgeOp, l.input[l.pos-2 : l.pos]}, nil
+	case c == '"':
+		return l.quoted()
+	case c == '<':
+		return token{}, fmt.Errorf("HTML strings are not supported")
+	}
+	start := l.pos
+	if c == '-' {
+		l.pos++ // negative numeral
+	}
+	for l.pos < len(l.input) && isIDChar(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	if l.pos == start || l.input[start:l.pos] == "-" {
+		return token{}, fmt.Errorf("unexpected character %q", c)
+	}
+	return token{tokenID, l.input[start:l.pos]}, nil
+}
+
+func isIDChar(r rune) bool {
+	return r == '_' || r == '.' || r >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (l *lexer) quoted() (token, error) {
+	var sb strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		switch c := l.input[l.pos]; c {
+		case '"':
+			l.pos++
+			return token{tokenQuoted, sb.String()}, nil
+		case '\\':
+			if l.pos+1 < len(l.input) {
+				l.pos++
+				switch l.input[l.pos] {
+				case '"', '\\':
+					sb.WriteByte(l.input[l.pos])
+				case 'n':
+					sb.WriteByte('\n')
+				case '\n':
+					// line continuation
+				default:
+					sb.WriteByte('\\')
+					sb.WriteByte(l.input[l.pos])
+				}
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string")
+}
+
+func (l *lexer) skipSpaceAndComments() {
+	for l.pos < len(l.input) {
+		rest := l.input[l.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			l.pos++
+		case strings.HasPrefix(rest, "//"), rest[0] == '#':
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				l.pos += end + 1
+			} else {
+				l.pos = len(l.input)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if end := strings.Index(rest[2:], "*/"); end >= 0 {
+				l.pos += end + 4
+			} else {
+				l.pos = len(l.input)
+			}
+		default:
+			return
+		}
+	}
+}
+
+type parser struct {
+	lexer  lexer
+	peeked *token
+	g      *Graph
+}
+
+func (p *parser) peek() (token, error) {
+	if p.peeked == nil {
+		t, err := p.lexer.next()
+		if err != nil {
+			return token{}, err
+		}
+		p.peeked = &t
+	}
+	return *p.peeked, nil
+}
+
+func (p *parser) take() (token, error) {
+	t, err := p.peek()
+	p.peeked = nil
+	return t, err
+}
+
+func (p *parser) expect(value string) error {
+	t, err := p.take()
+	if err != nil {
+		return err
+	}
+	if t.value != value || t.isID() {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) graph() (*Graph, error) {
+	t, err := p.take()
+	if err != nil {
+		return nil, err
+	}
+	if t.isKeyword("strict") {
+		if t, err = p.take(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case t.isKeyword("graph"):
+		p.g = New("", false)
+	case t.isKeyword("digraph"):
+		p.g = New("", true)
+	default:
+		return nil, fmt.Errorf("expected graph or digraph, got %q", t.value)
+	}
+	if t, err = p.peek(); err != nil {
+		return nil, err
+	}
+	if t.isID() {
+		p.take()
+		p.g.Name = t.value
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if _, err := p.statements(); err != nil {
+		return nil, err
+	}
+	if t, err = p.take(); err != nil {
+		return nil, err
+	}
+	if t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q after the graph", t.value)
+	}
+	return p.g, nil
+}
+
+// statements parses up to the closing brace and returns every node seen, so
+// `a -> {b c}` can link a to both.
+func (p *parser) statements() ([]string, error) {
+	var nodes []string
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.kind == tokenEOF:
+			return nil, fmt.Errorf("missing '}'")
+		case t.kind == tokenPunct && t.value == "}":
+			p.take()
+			return nodes, nil
+		case t.kind == tokenPunct && t.value == ";":
+			p.take()
+		default:
+			stmtNodes, err := p.statement()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, stmtNodes...)
+		}
+	}
+}
+
+func (p *parser) statement() ([]string, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	// `"node" [label=N]` is a node named node
+	if t.isKeyword("graph") || t.isKeyword("node") || t.isKeyword("edge") {
+		p.take()
+		_, err := p.attributes()
+		return nil, err
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	// graph level `key = value`
+	if t, err := p.peek(); err == nil && t.kind == tokenPunct && t.value == "=" {
+		p.take()
+		_, err := p.take()
+		return nil, err
+	}
+	for _, id := range left {
+		p.g.AddNode(id)
+	}
+
+	all := left
+	firstEdge := len(p.g.Edges)
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind != tokenEdgeOp {
+			break
+		}
+		p.take()
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		for _, from := range left {
+			for _, to := range right {
+				p.g.AddEdge(from, to)
+			}
+		}
+		all = append(all, right...)
+		left = right
+	}
+
+	attrs, err := p.attributes()
+	if err != nil {
+		return nil, err
+	}
+	if label, ok := attrs["label"]; ok {
+		if len(p.g.Edges) > firstEdge {
+			for i := firstEdge; i < len(p.g.Edges); i++ {
+				p.g.Edges[i].Label = label
+			}
+		} else {
+			for _, id := range all {
+				p.g.AddNode(id).Label = label
+			}
+		}
+	}
+	return all, nil
+}
+
+// operand is a node ID or a (possibly anonymous) subgraph.
+func (p *parser) operand() ([]string, error) {
+	t, err := p.take()
+	if err != nil {
+		return nil, err
+	}
+	if t.isKeyword("subgraph") {
+		if next, err := p.peek(); err == nil && next.isID() {
+			p.take()
+		}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		return p.statements()
+	}
+	if t.kind == tokenPunct && t.value == "{" {
+		return p.statements()
+	}
+	if !t.isID() {
+		return nil, fmt.Errorf("expected a node, got %q", t.value)
+	}
+	// ports (`a:n`) are accepted and ignored
+	if next, err := p.peek(); err == nil && next.kind == tokenPunct && next.value == ":" {
+		p.take()
+		if _, err := p.take(); err != nil {
+			return nil, err
+		}
+	}
+	return []string{t.value}, nil
+}
+
+func (p *parser) attributes() (map[string]string, error) {
+	attrs := map[string]string{}
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind != tokenPunct || t.value != "[" {
+			return attrs, nil
+		}
+		p.take()
+		for {
+			key, err := p.take()
+			if err != nil {
+				return nil, err
+			}
+			if key.kind == tokenPunct && key.value == "]" {
+				break
+			}
+			if key.kind == tokenPunct && (key.value == "," || key.value == ";") {
+				continue
+			}
+			if !key.isID() {
+				return nil, fmt.Errorf("expected an attribute name, got %q", key.value)
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := p.take()
+			if err != nil {
+				return nil, err
+			}
+			if !value.isID() {
+				return nil, fmt.Errorf("expected a value for %s, got %q", key.value, value.value)
+			}
+			attrs[key.value] = value.value
+		}
+	}
+}
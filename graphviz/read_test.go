@@ -0,0 +1,51 @@
+package graphviz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	g := New("strict", true)
+	g.AddNode(`a\b`).Label = `C:\dir "quoted"` + "\n" + `\l`
+	// keywords, quoted, with or without a label and edges
+	g.AddNode("graph")
+	g.AddNode("node").Label = "N"
+	g.AddNode("Edge")
+	g.AddNode("subgraph").Label = "S"
+	g.AddEdge(`a\b`, "graph").Label = `back\\slash`
+	var buf bytes.Buffer
+	if err := Write(&buf, g, Style{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(buf.String())
+	if err != nil {
+		t.Fatalf("Parse(%q): %s", buf.String(), err)
+	}
+	if got.Name != g.Name || len(got.Nodes) != len(g.Nodes) {
+		t.Fatalf("Parse(%q) = graph %q with nodes %+v, want %q with %+v", buf.String(), got.Name, got.Nodes, g.Name, g.Nodes)
+	}
+	for i, node := range g.Nodes {
+		if got.Nodes[i] != node {
+			t.Errorf("node %d = %+v, want %+v", i, got.Nodes[i], node)
+		}
+	}
+	if len(got.Edges) != 1 || got.Edges[0] != g.Edges[0] {
+		t.Errorf("edges = %+v, want %+v", got.Edges, g.Edges)
+	}
+}
+
+func TestIsDOT(t *testing.T) {
+	for input, want := range map[string]bool{
+		"digraph g { a -> b }":             true,
+		"// exported\n\nstrict digraph {}": true,
+		"/* comment */ GRAPH { a -- b }":   true,
+		"aaa: you hhh\nyou: bbb ccc\n":     false,
+		"graph: aaa\n":                     false,
+		"":                                 false,
+	} {
+		if got := IsDOT(input); got != want {
+			t.Errorf("IsDOT(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package graphviz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Style selects what gets highlighted when writing a graph. Colors are any
+// Graphviz color name or "#rrggbb"; empty colors fall back to the defaults.
+type Style struct {
+	// Waypoints are drawn as filled boxes (svr, dac, fft, out...)
+	Waypoints     []string
+	WaypointColor string
+	// Highlighted nodes are filled, e.g. the nodes on the path-count DAG
+	Highlighted    map[string]bool
+	HighlightColor string
+	// Highlighted edges are drawn bold, e.g. the edges of a spanning tree
+	HighlightedEdges []Edge
+	EdgeColor        string
+	// Dim draws everything not highlighted in grey
+	Dim bool
+}
+
+const (
+	defaultWaypointColor  = "gold"
+	defaultHighlightColor = "lightblue"
+	defaultEdgeColor      = "red"
+	dimColor              = "grey70"
+)
+
+// Write writes g in DOT format with the given highlighting.
+func Write(w io.Writer, g *Graph, style Style) error {
+	bw := bufio.NewWriter(w)
+	keyword, arrow := "graph", "--"
+	if g.Directed {
+		keyword, arrow = "digraph", "->"
+	}
+	fmt.Fprintf(bw, "%s %s {\n", keyword, quote(g.Name))
+
+	waypoints := make(map[string]bool, len(style.Waypoints))
+	for _, waypoint := range style.Waypoints {
+		waypoints[waypoint] = true
+	}
+	edges := make(map[Edge]bool, len(style.HighlightedEdges))
+	for _, edge := range style.HighlightedEdges {
+		edges[Edge{From: edge.From, To: edge.To}] = true
+		if !g.Directed {
+			edges[Edge{From: edge.To, To: edge.From}] = true
+		}
+	}
+
+	for _, node := range g.Nodes {
+		var attrs []string
+		if node.Label != "" {
+			attrs = append(attrs, "label="+quote(node.Label))
+		}
+		switch {
+		case waypoints[node.ID]:
+			attrs = append(attrs, "shape=box", "style=filled", "fillcolor="+quote(or(style.WaypointColor, defaultWaypointColor)))
+		case style.Highlighted[node.ID]:
+			attrs = append(attrs, "style=filled", "fillcolor="+quote(or(style.HighlightColor, defaultHighlightColor)))
+		case style.Dim:
+			attrs = append(attrs, "color="+dimColor, "fontcolor="+dimColor)
+		}
+		fmt.Fprintf(bw, "\t%s%s;\n", quote(node.ID), attributes(attrs))
+	}
+
+	for _, edge := range g.Edges {
+		var attrs []string
+		if edge.Label != "" {
+			attrs = append(attrs, "label="+quote(edge.Label))
+		}
+		switch {
+		case edges[Edge{From: edge.From, To: edge.To}]:
+			attrs = append(attrs, "penwidth=2.5", "color="+quote(or(style.EdgeColor, defaultEdgeColor)))
+		case style.Dim && !(style.Highlighted[edge.From] && style.Highlighted[edge.To]):
+			attrs = append(attrs, "color="+dimColor)
+		}
+		fmt.Fprintf(bw, "\t%s %s %s%s;\n", quote(edge.From), arrow, quote(edge.To), attributes(attrs))
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteFile writes g to the given path, see Write.
+func WriteFile(path string, g *Graph, style Style) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, g, style); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func attributes(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
+
+var plainID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$|^-?(\.[0-9]+|[0-9]+(\.[0-9]*)?)$`)
+
+// quote returns id as-is when DOT accepts it unquoted, quoted otherwise.
+func quote(id string) string {
+	if plainID.MatchString(id) && !isKeyword(id) {
+		return id
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(id) + `"`
+}
+
+func isKeyword(id string) bool {
+	switch strings.ToLower(id) {
+	case "graph", "digraph", "subgraph", "node", "edge", "strict":
+		return true
+	}
+	return false
+}
+
+func or(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
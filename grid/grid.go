@@ -0,0 +1,252 @@
+// Package grid is the 2D grid shared by the grid puzzles (day 4 rolls of
+// paper, day 7 tachyon manifold, day 12 present shapes and regions).
+package grid
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Point is a cell position, Row going down and Col going right.
+type Point struct {
+	Row, Col int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.Row + q.Row, p.Col + q.Col}
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.Row, p.Col)
+}
+
+var (
+	Up    = Point{-1, 0}
+	Down  = Point{1, 0}
+	Left  = Point{0, -1}
+	Right = Point{0, 1}
+
+	// orthogonal directions
+	Directions4 = []Point{Up, Right, Down, Left}
+	// orthogonal and diagonal directions
+	Directions8 = []Point{{-1, -1}, Up, {-1, 1}, Left, Right, {1, -1}, Down, {1, 1}}
+)
+
+// Grid is a rectangular grid of T stored row after row.
+type Grid[T any] struct {
+	height, width int
+	cells         []T
+}
+
+// New returns a height x width grid of zero values.
+func New[T any](height, width int) *Grid[T] {
+	return &Grid[T]{height: height, width: width, cells: make([]T, height*width)}
+}
+
+// Parse reads one row per line (LF or CRLF), converting each rune with cell.
+// Lines shorter than the longest one are padded with zero values. Only the
+// trailing newlines are dropped: leading blank lines are rows.
+func Parse[T any](input string, cell func(r rune) T) *Grid[T] {
+	lines := strings.Split(strings.TrimRight(input, "\r\n"), "\n")
+	width := 0
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+		width = max(width, len([]rune(lines[i])))
+	}
+	g := New[T](len(lines), width)
+	for i, line := range lines {
+		for j, r := range []rune(line) {
+			g.cells[i*width+j] = cell(r)
+		}
+	}
+	return g
+}
+
+// Runes reads the input as is.
+func Runes(input string) *Grid[rune] {
+	return Parse(input, func(r rune) rune { return r })
+}
+
+// Bools reads the input, true where the rune is on.
+func Bools(input string, on rune) *Grid[bool] {
+	return Parse(input, func(r rune) bool { return r == on })
+}
+
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+func (g *Grid[T]) In(p Point) bool {
+	return p.Row >= 0 && p.Row < g.height && p.Col >= 0 && p.Col < g.width
+}
+
+// At returns the cell at p, which must be in the grid.
+func (g *Grid[T]) At(p Point) T {
+	return g.cells[g.index(p)]
+}
+
+// Get returns the cell at p, or the zero value when p is out of the grid.
+func (g *Grid[T]) Get(p Point) T {
+	if !g.In(p) {
+		var zero T
+		return zero
+	}
+	return g.cells[g.index(p)]
+}
+
+// Set sets the cell at p, which must be in the grid.
+func (g *Grid[T]) Set(p Point, value T) {
+	g.cells[g.index(p)] = value
+}
+
+// Row returns row i, sharing the grid memory.
+func (g *Grid[T]) Row(i int) []T {
+	return g.cells[i*g.width : (i+1)*g.width]
+}
+
+func (g *Grid[T]) index(p Point) int {
+	if !g.In(p) {
+		panic(fmt.Sprintf("grid: %v is out of the %dx%d grid", p, g.height, g.width))
+	}
+	return p.Row*g.width + p.Col
+}
+
+// All iterates over the cells row after row.
+func (g *Grid[T]) All() iter.Seq2[Point, T] {
+	return func(yield func(Point, T) bool) {
+		for index, value := range g.cells {
+			if !yield(Point{index / g.width, index % g.width}, value) {
+				return
+			}
+		}
+	}
+}
+
+// Neighbors4 iterates over the orthogonal neighbors of p inside the grid.
+func (g *Grid[T]) Neighbors4(p Point) iter.Seq[Point] {
+	return g.neighbors(p, Directions4)
+}
+
+// Neighbors8 iterates over the orthogonal and diagonal neighbors of p inside
+// the grid.
+func (g *Grid[T]) Neighbors8(p Point) iter.Seq[Point] {
+	return g.neighbors(p, Directions8)
+}
+
+func (g *Grid[T]) neighbors(p Point, directions []Point) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		for _, d := range directions {
+			if n := p.Add(d); g.In(n) && !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// Find returns the first cell, row after row, matching the predicate.
+func (g *Grid[T]) Find(match func(T) bool) (Point, bool) {
+	for p, value := range g.All() {
+		if match(value) {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// Count returns the number of cells matching the predicate.
+func (g *Grid[T]) Count(match func(T) bool) int {
+	count := 0
+	for _, value := range g.cells {
+		if match(value) {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *Grid[T]) Clone() *Grid[T] {
+	return &Grid[T]{height: g.height, width: g.width, cells: append([]T(nil), g.cells...)}
+}
+
+// Rotate90 returns the grid rotated a quarter turn clockwise.
+func (g *Grid[T]) Rotate90() *Grid[T] {
+	rotated := New[T](g.width, g.height)
+	for p, value := range g.All() {
+		rotated.Set(Point{p.Col, g.height - 1 - p.Row}, value)
+	}
+	return rotated
+}
+
+// FlipHorizontal returns the grid mirrored left to right.
+func (g *Grid[T]) FlipHorizontal() *Grid[T] {
+	flipped := New[T](g.height, g.width)
+	for p, value := range g.All() {
+		flipped.Set(Point{p.Row, g.width - 1 - p.Col}, value)
+	}
+	return flipped
+}
+
+// FlipVertical returns the grid mirrored top to bottom.
+func (g *Grid[T]) FlipVertical() *Grid[T] {
+	flipped := New[T](g.height, g.width)
+	for p, value := range g.All() {
+		flipped.Set(Point{g.height - 1 - p.Row, p.Col}, value)
+	}
+	return flipped
+}
+
+// Format renders the grid one line per row, each cell as given by cell.
+func (g *Grid[T]) Format(cell func(T) rune) string {
+	var sb strings.Builder
+	for i := 0; i < g.height; i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		for _, value := range g.Row(i) {
+			sb.WriteRune(cell(value))
+		}
+	}
+	return sb.String()
+}
+
+// Print writes the Format of the grid, followed by a new line.
+func (g *Grid[T]) Print(w io.Writer, cell func(T) rune) {
+	fmt.Fprintln(w, g.Format(cell))
+}
+
+// String renders runes and bytes as is, bools as '#' and '.', and other
+// values with %v separated by spaces. Use Format for a custom rendering.
+func (g *Grid[T]) String() string {
+	var sb strings.Builder
+	for i := 0; i < g.height; i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		for j, value := range g.Row(i) {
+			switch v := any(value).(type) {
+			case rune:
+				sb.WriteRune(v)
+			case byte:
+				sb.WriteByte(v)
+			case bool:
+				if v {
+					sb.WriteByte('#')
+				} else {
+					sb.WriteByte('.')
+				}
+			default:
+				if j > 0 {
+					sb.WriteByte(' ')
+				}
+				fmt.Fprint(&sb, v)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Answers are the known answers of an input, by part ("1", "2"), for the
+// default settings of the day. The harness checks the runs against them.
+// They are stored next to the inputs, in answers-<kind>.txt files such as:
+//
+//	part1: 1123
+//	part2: 6695
+type Answers map[string]string
+
+// AnswersFile returns the answers file of an input kind (example, user).
+func AnswersFile(kind string) string {
+	return "answers-" + kind + ".txt"
+}
+
+// ReadAnswers reads an answers file, empty when it does not exist.
+func ReadAnswers(path string) (Answers, error) {
+	answers := Answers{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return answers, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, ":")
+		part, isPart := strings.CutPrefix(strings.TrimSpace(key), "part")
+		if !ok || !isPart || part == "" {
+			return nil, fmt.Errorf("%s:%d: expected part<n>: <answer>", path, line)
+		}
+		answers[part] = strings.TrimSpace(value)
+	}
+	return answers, scanner.Err()
+}
+
+// WriteAnswers writes an answers file.
+func WriteAnswers(path string, answers Answers) error {
+	return os.WriteFile(path, []byte(answers.String()), 0o644)
+}
+
+// String formats the answers as in the answers files, parts in order.
+func (a Answers) String() string {
+	var sb strings.Builder
+	for _, part := range []string{"1", "2"} {
+		if answer, ok := a[part]; ok {
+			fmt.Fprintf(&sb, "part%s: %s\n", part, answer)
+		}
+	}
+	return sb.String()
+}
+
+// Result is a run as recorded in the AOC_RESULTS file, for tools running
+// the days (see cmd/aoc).
+type Result struct {
+	Part     string        `json:"part"`
+	Input    string        `json:"input"`
+	Settings []string      `json:"settings,omitempty"`
+	Status   string        `json:"status"` // returned, failed (returned an error), panicked or timed out
+	Value    string        `json:"value"`
+	Expected string        `json:"expected,omitempty"`
+	Check    string        `json:"check,omitempty"` // pass or fail, when the answer is known
+	Duration time.Duration `json:"duration"`
+}
+
+// recordResult appends a result to the AOC_RESULTS file, when set.
+func recordResult(r Result) {
+	path := os.Getenv("AOC_RESULTS")
+	if path == "" {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		logf("results: %s", err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		logf("results: %s", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		logf("results: %s", err)
+	}
+}
@@ -0,0 +1,207 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jpillora/ansi"
+)
+
+// benchRecord is a line of the history file.
+type benchRecord struct {
+	Commit   string        `json:"commit"` // with a "-dirty" suffix for uncommitted changes
+	Date     time.Time     `json:"date"`
+	Part     string        `json:"part"`
+	Input    string        `json:"input"`
+	Settings []string      `json:"settings,omitempty"`
+	Runs     int           `json:"runs"`
+	Min      time.Duration `json:"min"`
+	Median   time.Duration `json:"median"`
+	P95      time.Duration `json:"p95"`
+	Stddev   time.Duration `json:"stddev"`
+}
+
+// benchConfig is read from the environment: BENCH=<n> runs each part n
+// more times after its usual run, and BENCH_WARMUP (1 by default) untimed
+// times before that, then prints the statistics of the n timings. They are
+// appended to BENCH_HISTORY (bench-history.jsonl in the day directory by
+// default), and a median more than BENCH_THRESHOLD percent (10 by default)
+// slower than the previous record of the same part, input and settings is
+// flagged as a regression.
+type benchConfig struct {
+	runs, warmup int
+	threshold    float64
+	history      string
+}
+
+func readBenchConfig() (*benchConfig, error) {
+	env := os.Getenv("BENCH")
+	if env == "" || env == "0" {
+		return nil, nil
+	}
+	b := &benchConfig{warmup: 1, threshold: 10, history: "bench-history.jsonl"}
+	var err error
+	if b.runs, err = strconv.Atoi(env); err != nil || b.runs < 1 {
+		return nil, fmt.Errorf("invalid BENCH=%s", env)
+	}
+	if env := os.Getenv("BENCH_WARMUP"); env != "" {
+		if b.warmup, err = strconv.Atoi(env); err != nil || b.warmup < 0 {
+			return nil, fmt.Errorf("invalid BENCH_WARMUP=%s", env)
+		}
+	}
+	if env := os.Getenv("BENCH_THRESHOLD"); env != "" {
+		if b.threshold, err = strconv.ParseFloat(env, 64); err != nil {
+			return nil, fmt.Errorf("invalid BENCH_THRESHOLD=%s", env)
+		}
+	}
+	if env := os.Getenv("BENCH_HISTORY"); env != "" {
+		b.history = env
+	}
+	return b, nil
+}
+
+// bench times the runs of a part which already succeeded once, and reports
+// whether one of them was abandoned (see call).
+func (c *config) bench(fn RunContextFn, part, file, input string, want any) (abandoned bool) {
+	var timings []time.Duration
+	for i := range c.benchmark.warmup + c.benchmark.runs {
+		ts := time.Now()
+		o := c.call(fn, part, input)
+		elapsed := time.Since(ts)
+		switch {
+		case o.abandoned:
+			logf("bench: run %d has no result after %s", i+1, c.timeout)
+			return true
+		case o.stack != nil:
+			logf("bench: run %d panicked: %v", i+1, o.panic)
+			return false
+		case o.timedOut:
+			logf("bench: run %d timed out after %s", i+1, c.timeout)
+			return false
+		case fmt.Sprint(o.value) != fmt.Sprint(want):
+			logf("bench: run %d returned %v instead of %v", i+1, o.value, want)
+			return false
+		}
+		if i >= c.benchmark.warmup {
+			timings = append(timings, elapsed)
+		}
+	}
+	record := benchRecord{
+		Commit:   gitCommit(),
+		Date:     time.Now().UTC().Truncate(time.Second),
+		Part:     part,
+		Input:    file,
+		Settings: c.partSettings(part),
+	}
+	record.measure(timings)
+	fmt.Print(ansi.Black.String(fmt.Sprintf("bench(part%s, %s) %d runs: ", part, file, record.Runs)))
+	fmt.Print(ansi.Black.String("min "), ansi.Cyan.String(record.Min.String()))
+	fmt.Print(ansi.Black.String(", median "), ansi.Cyan.String(record.Median.String()))
+	fmt.Print(ansi.Black.String(", p95 "), ansi.Cyan.String(record.P95.String()))
+	fmt.Print(ansi.Black.String(", stddev "), ansi.Cyan.String(record.Stddev.String()))
+	fmt.Println()
+	previous, err := lastBenchRecord(c.benchmark.history, record)
+	if err != nil {
+		logf("bench: %s", err)
+	}
+	if previous != nil {
+		change := 100 * (float64(record.Median) - float64(previous.Median)) / float64(previous.Median)
+		line := fmt.Sprintf("median %+.1f%% vs %s at %s", change, previous.Median, previous.Commit)
+		if change > c.benchmark.threshold {
+			fmt.Println(ansi.Red.String("regression: " + line))
+		} else {
+			logf("%s", line)
+		}
+	}
+	if err := appendBenchRecord(c.benchmark.history, record); err != nil {
+		logf("bench: %s", err)
+	}
+	return false
+}
+
+func (r *benchRecord) measure(timings []time.Duration) {
+	slices.Sort(timings)
+	n := len(timings)
+	r.Runs = n
+	r.Min = timings[0]
+	if n%2 == 1 {
+		r.Median = timings[n/2]
+	} else {
+		r.Median = (timings[n/2-1] + timings[n/2]) / 2
+	}
+	// nearest rank
+	r.P95 = timings[int(math.Ceil(0.95*float64(n)))-1]
+	if n > 1 {
+		var sum, squares float64
+		for _, t := range timings {
+			sum += float64(t)
+		}
+		mean := sum / float64(n)
+		for _, t := range timings {
+			squares += (float64(t) - mean) * (float64(t) - mean)
+		}
+		r.Stddev = time.Duration(math.Sqrt(squares / float64(n-1)))
+	}
+}
+
+// gitCommit returns the short hash of HEAD, "unknown" outside of a
+// repository.
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	commit := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(status) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// lastBenchRecord returns the latest record of the same part, input and
+// settings, nil when there is none.
+func lastBenchRecord(path string, of benchRecord) (*benchRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var last *benchRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r benchRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if r.Part == of.Part && r.Input == of.Input && slices.Equal(r.Settings, of.Settings) {
+			last = &r
+		}
+	}
+	return last, scanner.Err()
+}
+
+func appendBenchRecord(path string, r benchRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,3 @@
+package harness
+
+var RedirectDownloads = redirectDownloads
@@ -0,0 +1,354 @@
+// Package harness is a drop-in replacement for puzzler's aoc.Harness.
+//
+// Watching code.go, downloading the README and the inputs is still done by
+// puzzler, which re-runs `go run code.go` with AOC_HARNESS=1 on every save.
+// Its downloads go to AOC_BASE_URL instead of adventofcode.com when set.
+// That child process is handled here instead, so the runs can be extended
+// for this repo (settings shown next to each result, ...).
+//
+// Each run gets a context.Context, cancelled after TIMEOUT (e.g. TIMEOUT=30s)
+// when set. A run which panics or times out is reported as failed with its
+// stack trace, and the next ones go on: the watcher is never brought down.
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jpillora/ansi"
+	"github.com/jpillora/puzzler/harness/aoc"
+)
+
+// RunFn is the usual `run` function of a day.
+type RunFn func(part2 bool, input string) any
+
+// RunContextFn is a `run` function which returns early once ctx is done,
+// usually with ctx.Err(). Either way, an error returned fails the run.
+type RunContextFn func(ctx context.Context, part2 bool, input string) any
+
+// Option customizes a harness run.
+type Option func(*config)
+
+type config struct {
+	settings  []setting
+	timeout   time.Duration
+	benchmark *benchConfig
+}
+
+type setting struct {
+	part string // "" for both parts
+	text string // name=value
+}
+
+// Setting reports a solution setting (e.g. a mode picked through an env
+// variable) next to every result, as `run(part1, input-user, name=value)`.
+func Setting(name, value string) Option {
+	return PartSetting(0, name, value)
+}
+
+// PartSetting reports a setting next to the results of one part (1 or 2)
+// only, for a setting without effect on the other part. Part 0 is both.
+func PartSetting(part int, name, value string) Option {
+	return func(c *config) {
+		s := setting{text: name + "=" + value}
+		if part != 0 {
+			s.part = strconv.Itoa(part)
+		}
+		c.settings = append(c.settings, s)
+	}
+}
+
+// partSettings returns the settings reported for a part.
+func (c *config) partSettings(part string) []string {
+	var settings []string
+	for _, s := range c.settings {
+		if s.part == "" || s.part == part {
+			settings = append(settings, s.text)
+		}
+	}
+	return settings
+}
+
+// Timeout sets the default deadline of each run, overridden by TIMEOUT.
+func Timeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// Harness runs fn the same way aoc.Harness does, see the README.
+func Harness(fn RunFn, opts ...Option) {
+	HarnessContext(func(_ context.Context, part2 bool, input string) any {
+		return fn(part2, input)
+	}, opts...)
+}
+
+// HarnessContext runs fn like Harness, fn being cancelled at the deadline.
+// A run function ignoring its context is abandoned at the deadline, along
+// with the runs following it.
+func HarnessContext(fn RunContextFn, opts ...Option) {
+	if os.Getenv("AOC_HARNESS") != "1" {
+		// parent process: puzzler's watcher, which spawns us back
+		if err := redirectDownloads(); err != nil {
+			log.Fatalf("harness: %s", err)
+		}
+		aoc.Harness(func(part2 bool, input string) any {
+			return fn(context.Background(), part2, input)
+		})
+		return
+	}
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if env := os.Getenv("TIMEOUT"); env != "" {
+		timeout, err := time.ParseDuration(env)
+		if err != nil {
+			log.Fatalf("harness: invalid TIMEOUT=%s: %s", env, err)
+		}
+		c.timeout = timeout
+	}
+	benchmark, err := readBenchConfig()
+	if err != nil {
+		log.Fatalf("harness: %s", err)
+	}
+	c.benchmark = benchmark
+	closeTrace, err := setupTrace()
+	if err != nil {
+		log.Fatalf("harness: %s", err)
+	}
+	err = c.runAll(fn)
+	closeTrace()
+	if err != nil {
+		log.Fatalf("harness: %s", err)
+	}
+}
+
+func (c *config) runAll(fn RunContextFn) error {
+	inputs := 0
+	runs := 0
+	// user can optionally provide PART=1/2 INPUT=example/user
+	doPart := os.Getenv("PART")
+	doInput := os.Getenv("INPUT")
+	// part 2 is only known to be unlocked when a session is provided
+	hasSession := os.Getenv("AOC_SESSION") != ""
+	noPart2 := hasSession && os.Getenv("AOC_PART2") != "true"
+	for _, part := range []string{"1", "2"} {
+		hasPart := part == "1" || !noPart2
+		skipPart := !hasPart || (doPart != "" && doPart != part)
+		for _, kind := range []string{"example", "user"} {
+			skipInput := doInput != "" && doInput != kind
+			file, input, ok := readInput(part, kind)
+			if !ok {
+				continue
+			}
+			inputs++
+			if skipPart || skipInput {
+				continue
+			}
+			value, ran, success, abandoned := c.runPart(fn, part, file, input)
+			if ran {
+				runs++
+			}
+			if ran && success && c.benchmark != nil {
+				abandoned = c.bench(fn, part, file, input, value)
+			}
+			if abandoned {
+				logf("the run is still going, skipping the next ones")
+				return nil
+			}
+			if !success {
+				break
+			}
+		}
+	}
+	if inputs == 0 {
+		return errors.New("no input text files found")
+	}
+	if runs == 0 {
+		logf("skipped all parts/inputs")
+	}
+	return nil
+}
+
+// readInput picks input-<kind>2.txt for part 2 when it exists, and
+// input-<kind>.txt otherwise.
+func readInput(part, kind string) (file, input string, ok bool) {
+	file = "input-" + kind
+	if part == "2" {
+		if b, err := os.ReadFile(file + "2.txt"); err == nil && len(b) > 0 {
+			return file + "2", string(b), true
+		}
+	}
+	b, err := os.ReadFile(file + ".txt")
+	if err != nil || len(b) == 0 {
+		return "", "", false
+	}
+	return file, string(b), true
+}
+
+// gracePeriod is left to a run function to return once its context is done,
+// before it is abandoned.
+const gracePeriod = time.Second
+
+// outcome is what a run function returned, or the panic it raised.
+type outcome struct {
+	value any
+	panic any
+	stack []byte
+	// timedOut is set when the deadline passed before fn returned, and
+	// abandoned when it did not return within the grace period either
+	timedOut, abandoned bool
+}
+
+// call runs fn in a goroutine, with the TIMEOUT deadline, and waits for it
+// until gracePeriod after the deadline.
+func (c *config) call(fn RunContextFn, part, input string) outcome {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+	defer cancel()
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{panic: r, stack: debug.Stack()}
+			}
+		}()
+		done <- outcome{value: fn(ctx, part == "2", input)}
+	}()
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		select {
+		case o = <-done:
+		case <-time.After(gracePeriod):
+			return outcome{timedOut: true, abandoned: true}
+		}
+	}
+	o.timedOut = ctx.Err() != nil
+	return o
+}
+
+func (c *config) runPart(fn RunContextFn, part, file, input string) (value any, ran, success, abandoned bool) {
+	current.part, current.file = part, file
+	current.trace = runTracer(part, file)
+	prof, err := startProfile(part, file)
+	if err != nil {
+		logf("profile: %s", err)
+	}
+	// after the result is printed
+	defer prof.stop()
+	ts := time.Now()
+	o := c.call(fn, part, input)
+	switch {
+	case o.abandoned:
+		c.result(part, file, ts, "timed out", fmt.Sprintf("no result after %s", c.timeout))
+		return nil, true, false, true
+	case o.stack != nil:
+		printStack(o.stack)
+		c.result(part, file, ts, "panicked", o.panic)
+		return nil, true, false, false
+	case o.timedOut:
+		c.result(part, file, ts, "timed out", o.value)
+		return o.value, true, false, false
+	}
+	if err, ok := o.value.(error); ok {
+		c.result(part, file, ts, "failed", err)
+		return err, true, false, false
+	}
+	s, ok := o.value.(string)
+	if o.value == nil || ok && (s == "skip" || s == "not implemented") {
+		return o.value, false, true, false
+	}
+	c.result(part, file, ts, "returned", o.value)
+	return o.value, true, true, false
+}
+
+func (c *config) result(part, file string, ts time.Time, status string, value any) {
+	r := Result{
+		Part:     part,
+		Input:    file,
+		Settings: c.partSettings(part),
+		Status:   status,
+		Value:    fmt.Sprint(value),
+		Duration: time.Since(ts),
+	}
+	if status == "returned" {
+		r.Expected, r.Check = check(part, file, r.Value)
+	}
+	recordResult(r)
+	fmt.Print(ansi.Black.String("run(part"))
+	fmt.Print(ansi.Cyan.String(part))
+	fmt.Print(ansi.Black.String(", "))
+	fmt.Print(ansi.Green.String(file))
+	for _, setting := range r.Settings {
+		fmt.Print(ansi.Black.String(", "))
+		fmt.Print(ansi.Yellow.String(setting))
+	}
+	fmt.Print(ansi.Black.String(") "))
+	if status == "returned" {
+		fmt.Print(ansi.Green.String(status))
+	} else {
+		fmt.Print(ansi.Red.String(status))
+	}
+	fmt.Print(ansi.Black.String(" in "))
+	fmt.Print(ansi.Cyan.String(since(ts)))
+	fmt.Print(ansi.Black.String(" => "))
+	fmt.Print(output(value))
+	switch r.Check {
+	case "pass":
+		fmt.Print(ansi.Green.String(" ✓"))
+	case "fail":
+		fmt.Print(ansi.Red.String(" ✗ expected " + r.Expected))
+	}
+	fmt.Println()
+}
+
+// check compares a value with the answer of its input recorded in
+// answers-example.txt or answers-user.txt, the answer of part 2 being also
+// the one of input-<kind>2.txt.
+func check(part, file, value string) (expected, result string) {
+	kind := strings.TrimSuffix(strings.TrimPrefix(file, "input-"), "2")
+	answers, err := ReadAnswers(AnswersFile(kind))
+	if err != nil {
+		logf("%s", err)
+		return "", ""
+	}
+	expected, ok := answers[part]
+	switch {
+	case !ok:
+		return "", ""
+	case strings.TrimSpace(value) == expected:
+		return expected, "pass"
+	}
+	return expected, "fail"
+}
+
+var fractionalPart = regexp.MustCompile(`\.\d+`)
+
+func since(ts time.Time) string {
+	return fractionalPart.ReplaceAllString(time.Since(ts).String(), "")
+}
+
+func output(v any) string {
+	out := ansi.Bright.String(fmt.Sprintf("%v", v))
+	if strings.Contains(out, "\n") {
+		out = "\n" + out
+	}
+	return out
+}
+
+func logf(format string, args ...any) {
+	fmt.Printf(ansi.Black.String(format+"\n"), args...)
+}
@@ -0,0 +1,103 @@
+package harness
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// results runs fn on part 1 and returns the results it recorded.
+func results(t *testing.T, c *config, fn RunContextFn) (success bool, recorded []Result) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	t.Setenv("AOC_RESULTS", path)
+	_, _, success, _ = c.runPart(fn, "1", "input-example", "")
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		recorded = append(recorded, r)
+	}
+	return success, recorded
+}
+
+func TestRunPartStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     RunContextFn
+		status string
+		value  string
+	}{
+		{"answer", func(ctx context.Context, part2 bool, input string) any { return 42 }, "returned", "42"},
+		{"error", func(ctx context.Context, part2 bool, input string) any {
+			return errors.New("problem 0: unknown operator '%'")
+		}, "failed", "problem 0: unknown operator '%'"},
+		{"panic", func(ctx context.Context, part2 bool, input string) any { panic("boom") }, "panicked", "boom"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			success, recorded := results(t, &config{}, test.fn)
+			if len(recorded) != 1 || recorded[0].Status != test.status || recorded[0].Value != test.value {
+				t.Fatalf("results = %+v, want status %q with %q", recorded, test.status, test.value)
+			}
+			if success != (test.status == "returned") {
+				t.Errorf("success = %v", success)
+			}
+		})
+	}
+}
+
+func TestRunPartTimeout(t *testing.T) {
+	wait := func(ctx context.Context, part2 bool, input string) any {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	success, recorded := results(t, &config{timeout: 10 * time.Millisecond}, wait)
+	if success || len(recorded) != 1 || recorded[0].Status != "timed out" {
+		t.Errorf("results = %+v, success %v, want timed out", recorded, success)
+	}
+}
+
+// TestBenchTimeout checks a bench run ignoring its context is given up like
+// the usual runs, rather than hanging BENCH.
+func TestBenchTimeout(t *testing.T) {
+	stuck := make(chan struct{})
+	defer close(stuck)
+	calls := 0
+	fn := func(ctx context.Context, part2 bool, input string) any {
+		calls++
+		if calls > 1 {
+			<-stuck
+		}
+		return 42
+	}
+	c := &config{
+		timeout:   10 * time.Millisecond,
+		benchmark: &benchConfig{runs: 3, history: filepath.Join(t.TempDir(), "bench-history.jsonl")},
+	}
+	if success, _ := results(t, c, fn); !success {
+		t.Fatal("the first run failed")
+	}
+	done := make(chan bool, 1)
+	go func() { done <- c.bench(fn, "1", "input-example", "", 42) }()
+	select {
+	case abandoned := <-done:
+		if !abandoned {
+			t.Error("bench did not report the run as abandoned")
+		}
+	case <-time.After(gracePeriod + time.Second):
+		t.Fatal("bench is still waiting for the run")
+	}
+}
@@ -0,0 +1,218 @@
+package harness
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"slices"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/jpillora/ansi"
+)
+
+// profiler records the CPU and memory usage of a run, with PROFILE=<dir>
+// (PROFILE=1 for the day directory). Each run writes
+// <dir>/cpu-part1-input-user.pprof and <dir>/heap-part1-input-user.pprof,
+// then prints its allocations and the PROFILE_TOP (10 by default) functions
+// using the most CPU. Use PART and INPUT to profile a single run.
+type profiler struct {
+	cpuPath, heapPath string
+	cpu               *os.File
+	before            [2]metrics.Sample // allocated objects and bytes
+	peak              uint64
+	stopSampling      chan struct{}
+	sampled           chan struct{}
+}
+
+var allocMetrics = [2]string{"/gc/heap/allocs:objects", "/gc/heap/allocs:bytes"}
+
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// startProfile starts profiling, or returns nil when PROFILE is not set.
+func startProfile(part, file string) (*profiler, error) {
+	dir := os.Getenv("PROFILE")
+	if dir == "" || dir == "0" {
+		return nil, nil
+	}
+	if dir == "1" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("part%s-%s.pprof", part, file)
+	p := &profiler{
+		cpuPath:      filepath.Join(dir, "cpu-"+name),
+		heapPath:     filepath.Join(dir, "heap-"+name),
+		stopSampling: make(chan struct{}),
+		sampled:      make(chan struct{}),
+	}
+	cpu, err := os.Create(p.cpuPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, err
+	}
+	p.cpu = cpu
+	runtime.GC()
+	p.before = readAllocs()
+	go p.samplePeak()
+	return p, nil
+}
+
+func readAllocs() [2]metrics.Sample {
+	samples := [2]metrics.Sample{{Name: allocMetrics[0]}, {Name: allocMetrics[1]}}
+	metrics.Read(samples[:])
+	return samples
+}
+
+// samplePeak polls the live heap, which no runtime statistic keeps the peak
+// of. Reading metrics does not stop the world, unlike runtime.ReadMemStats.
+func (p *profiler) samplePeak() {
+	defer close(p.sampled)
+	sample := []metrics.Sample{{Name: heapMetric}}
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for {
+		metrics.Read(sample)
+		p.peak = max(p.peak, sample[0].Value.Uint64())
+		select {
+		case <-p.stopSampling:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop ends the profiles and prints the report of the run.
+func (p *profiler) stop() {
+	if p == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	p.cpu.Close()
+	close(p.stopSampling)
+	<-p.sampled
+	after := readAllocs()
+	objects := after[0].Value.Uint64() - p.before[0].Value.Uint64()
+	bytes := after[1].Value.Uint64() - p.before[1].Value.Uint64()
+	if err := writeHeapProfile(p.heapPath); err != nil {
+		logf("profile: %s", err)
+	}
+	fmt.Printf("%s %s %s %s %s %s %s %s\n",
+		ansi.Black.String("profile:"), ansi.Cyan.String(fmt.Sprint(objects)),
+		ansi.Black.String("allocations,"), ansi.Cyan.String(byteSize(bytes)),
+		ansi.Black.String("allocated, peak heap"), ansi.Cyan.String(byteSize(p.peak)),
+		ansi.Black.String("=>"), ansi.Green.String(p.cpuPath+", "+p.heapPath))
+	if err := printTop(p.cpuPath); err != nil {
+		logf("profile: %s", err)
+	}
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
+
+// printTop prints the functions with the most CPU time in the profile, like
+// `go tool pprof -top`: flat is the time spent in the function itself, cum
+// also counts the functions it calls.
+func printTop(path string) error {
+	n := 10
+	if env := os.Getenv("PROFILE_TOP"); env != "" {
+		if _, err := fmt.Sscan(env, &n); err != nil {
+			return fmt.Errorf("invalid PROFILE_TOP=%s", env)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prof, err := profile.Parse(f)
+	if err != nil {
+		return err
+	}
+	// the last sample type is the CPU time, after the sample count
+	valueIndex := len(prof.SampleType) - 1
+	type function struct {
+		name      string
+		flat, cum int64
+	}
+	functions := map[string]*function{}
+	get := func(name string) *function {
+		if functions[name] == nil {
+			functions[name] = &function{name: name}
+		}
+		return functions[name]
+	}
+	var total int64
+	for _, sample := range prof.Sample {
+		value := sample.Value[valueIndex]
+		total += value
+		seen := map[string]bool{}
+		for i, location := range sample.Location {
+			// inlined calls share a location, the leaf first
+			for j, line := range location.Line {
+				if line.Function == nil {
+					continue
+				}
+				name := line.Function.Name
+				if i == 0 && j == 0 {
+					get(name).flat += value
+				}
+				if !seen[name] {
+					seen[name] = true
+					get(name).cum += value
+				}
+			}
+		}
+	}
+	if total == 0 {
+		logf("profile: no CPU samples, the run was too short")
+		return nil
+	}
+	sorted := slices.SortedFunc(func(yield func(*function) bool) {
+		for _, f := range functions {
+			if !yield(f) {
+				return
+			}
+		}
+	}, func(a, b *function) int {
+		return cmp.Or(cmp.Compare(b.flat, a.flat), cmp.Compare(b.cum, a.cum), cmp.Compare(a.name, b.name))
+	})
+	percent := func(v int64) string {
+		return fmt.Sprintf("%.2f%%", 100*float64(v)/float64(total))
+	}
+	fmt.Println(ansi.Black.String(fmt.Sprintf("%10s %7s %10s %7s  (of %s)", "flat", "flat%", "cum", "cum%", time.Duration(total))))
+	for _, f := range sorted[:min(n, len(sorted))] {
+		fmt.Printf("%10s %7s %10s %7s  %s\n", time.Duration(f.flat), percent(f.flat),
+			time.Duration(f.cum), percent(f.cum), ansi.Bright.String(f.name))
+	}
+	return nil
+}
+
+func byteSize(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,68 @@
+package harness
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBaseURL is the Advent of Code server.
+const DefaultBaseURL = "https://adventofcode.com"
+
+// BaseURL returns the Advent of Code server to talk to: AOC_BASE_URL when
+// set (such as a local aocstub server), adventofcode.com otherwise.
+func BaseURL() string {
+	if base := os.Getenv("AOC_BASE_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return DefaultBaseURL
+}
+
+// redirectDownloads sends the downloads of puzzler's watcher (the README and
+// the user input, fetched from https://adventofcode.com with
+// http.DefaultClient) to BaseURL instead.
+func redirectDownloads() error {
+	base := BaseURL()
+	if base == DefaultBaseURL {
+		return nil
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid AOC_BASE_URL=%s", base)
+	}
+	http.DefaultClient.Transport = rebaseTransport{base: u, next: http.DefaultTransport}
+	// puzzler caches the downloads in os.TempDir() by URL, forever when
+	// adventofcode.com is unreachable: a directory of their own keeps the
+	// responses of the other server apart, and its stale ones are dropped
+	// so that each start downloads again
+	dir := filepath.Join(os.TempDir(), "aoc-in-go-"+strings.NewReplacer(":", "-", "/", "-").Replace(u.Host))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, "cache-pzlr-*"))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+	return os.Setenv("TMPDIR", dir)
+}
+
+// rebaseTransport sends the requests for adventofcode.com to another server.
+type rebaseTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t rebaseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "adventofcode.com" {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.base.Scheme
+	req.URL.Host = t.base.Host
+	req.URL.Path = strings.TrimSuffix(t.base.Path, "/") + req.URL.Path
+	req.Host = ""
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,81 @@
+package harness_test
+
+import (
+	"aoc-in-go/aocstub"
+	"aoc-in-go/harness"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRedirectDownloads fetches what puzzler does, the way it does, from the
+// stub server.
+func TestRedirectDownloads(t *testing.T) {
+	stub := aocstub.New(&aocstub.Puzzle{Year: 2025, Day: 1, Part1: "<p>part 1</p>", Input: "L68\nR48\n"})
+	defer stub.Close()
+	t.Setenv("AOC_BASE_URL", stub.URL+"/")
+	t.Setenv("TMPDIR", t.TempDir())
+	defer func(transport http.RoundTripper) { http.DefaultClient.Transport = transport }(http.DefaultClient.Transport)
+
+	stale := filepath.Join(os.TempDir(), "aoc-in-go-"+strings.NewReplacer(":", "-").Replace(strings.TrimPrefix(stub.URL, "http://")))
+	if err := os.MkdirAll(stale, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stale, "cache-pzlr-0123.bin"), []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.RedirectDownloads(); err != nil {
+		t.Fatal(err)
+	}
+	if os.TempDir() != stale {
+		t.Errorf("TMPDIR = %s, want %s", os.TempDir(), stale)
+	}
+	if _, err := os.Stat(filepath.Join(stale, "cache-pzlr-0123.bin")); !os.IsNotExist(err) {
+		t.Errorf("stale cache kept: %v", err)
+	}
+
+	get := func(url string) string {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: "session", Value: "test-session"})
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: %s", url, resp.Status)
+		}
+		return string(body)
+	}
+	if page := get("https://adventofcode.com/2025/day/1"); !strings.Contains(page, "<p>part 1</p>") {
+		t.Errorf("puzzle page = %q", page)
+	}
+	if input := get("https://adventofcode.com/2025/day/1/input"); input != "L68\nR48\n" {
+		t.Errorf("input = %q", input)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	t.Setenv("AOC_BASE_URL", "")
+	if got := harness.BaseURL(); got != harness.DefaultBaseURL {
+		t.Errorf("BaseURL() = %s, want %s", got, harness.DefaultBaseURL)
+	}
+	if err := harness.RedirectDownloads(); err != nil {
+		t.Errorf("RedirectDownloads() without AOC_BASE_URL = %v", err)
+	}
+	t.Setenv("AOC_BASE_URL", "127.0.0.1:8911")
+	if err := harness.RedirectDownloads(); err == nil {
+		t.Error("RedirectDownloads() of a URL without scheme: no error")
+	}
+}
@@ -0,0 +1,116 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// current is the run in progress, which reports and traces are attached to.
+var current struct {
+	part, file string
+	trace      *slog.Logger
+}
+
+// Reporting tells whether a report is asked, with REPORT=table or
+// REPORT=json, so that solutions only build it when needed.
+func Reporting() bool {
+	return os.Getenv("REPORT") != ""
+}
+
+// Report prints details of the current run, one row per item (e.g. per
+// ingredient), when Reporting. Rows are structs whose fields are the
+// columns, named after their json tag.
+//
+// REPORT=table prints an aligned table before the run result, REPORT=json
+// prints one JSON object per run: {"part":"1","input":"input-user","rows":[...]}.
+func Report[T any](rows []T) {
+	switch format := os.Getenv("REPORT"); format {
+	case "":
+	case "json":
+		reportJSON(rows)
+	case "table":
+		reportTable(rows)
+	default:
+		logf("unknown REPORT=%s, expected table or json", format)
+	}
+}
+
+func reportJSON[T any](rows []T) {
+	if rows == nil {
+		rows = []T{}
+	}
+	b, err := json.Marshal(struct {
+		Part  string `json:"part"`
+		Input string `json:"input"`
+		Rows  []T    `json:"rows"`
+	}{current.part, current.file, rows})
+	if err != nil {
+		logf("report: %s", err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
+func reportTable[T any](rows []T) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		logf("report: rows are %s, not structs", t)
+		return
+	}
+	logf("report(part%s, %s): %d rows", current.part, current.file, len(rows))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var header []string
+	for _, field := range reflect.VisibleFields(t) {
+		if name, ok := columnName(field); ok {
+			header = append(header, strings.ToUpper(name))
+		}
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		var cells []string
+		for _, field := range reflect.VisibleFields(t) {
+			if _, ok := columnName(field); ok {
+				cells = append(cells, cell(v.FieldByIndex(field.Index)))
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
+
+// columnName is the json name of an exported field, which is skipped with
+// `json:"-"`.
+func columnName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+	return name, true
+}
+
+// cell prints slices as comma separated values, "-" when empty.
+func cell(v reflect.Value) string {
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return fmt.Sprint(v.Interface())
+	}
+	if v.Len() == 0 {
+		return "-"
+	}
+	values := make([]string, v.Len())
+	for i := range v.Len() {
+		values[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,46 @@
+package harness
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jpillora/ansi"
+	"github.com/maruel/panicparse/v2/stack"
+)
+
+// printStack prints the frames of a panicking run, from the panic down to
+// the run function: the frames of the solution stand out, the ones of the
+// runtime and libraries are greyed. The raw stack is printed when it cannot
+// be parsed.
+func printStack(raw []byte) {
+	opts := stack.DefaultOpts()
+	opts.AnalyzeSources = false
+	snapshot, _, err := stack.ScanSnapshot(bytes.NewReader(raw), io.Discard, opts)
+	if err != nil && err != io.EOF || snapshot == nil || len(snapshot.Goroutines) == 0 {
+		os.Stdout.Write(raw)
+		return
+	}
+	calls := snapshot.Goroutines[0].Stack.Calls
+	// drop the recovery frames, above the panic
+	for i, call := range calls {
+		if call.Func.Complete == "panic" || call.Func.Complete == "runtime.gopanic" {
+			calls = calls[i+1:]
+			break
+		}
+	}
+	for _, call := range calls {
+		if call.Func.ImportPath == "aoc-in-go/harness" {
+			// the harness calling the run function
+			break
+		}
+		line := fmt.Sprintf("  %s:%d %s(%s)", call.SrcName, call.Line, call.Func.Complete, call.Args.String())
+		if call.Func.IsPkgMain || strings.HasPrefix(call.Func.ImportPath, "aoc-in-go/") {
+			fmt.Println(ansi.Yellow.String(line))
+		} else {
+			fmt.Println(ansi.Black.String(line))
+		}
+	}
+}
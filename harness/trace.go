@@ -0,0 +1,70 @@
+package harness
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// tracer is off unless enabled by setupTrace.
+var (
+	tracer  = slog.New(slog.DiscardHandler)
+	tracing bool
+)
+
+// setupTrace enables tracing with TRACE=1, or TRACE=<level> (debug, info,
+// warn, error) to drop the lower levels. Traces go to stderr, not to mix
+// with the results on stdout, or are appended to TRACE_FILE when set.
+func setupTrace() (close func(), err error) {
+	close = func() {}
+	env := os.Getenv("TRACE")
+	if env == "" || env == "0" {
+		return close, nil
+	}
+	level := slog.LevelDebug
+	if env != "1" {
+		if err := level.UnmarshalText([]byte(env)); err != nil {
+			return close, fmt.Errorf("invalid TRACE=%s: %w", env, err)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var w io.Writer = os.Stderr
+	if path := os.Getenv("TRACE_FILE"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return close, err
+		}
+		w, close = f, func() { f.Close() }
+	} else {
+		// the time is noise in a terminal
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		}
+	}
+	tracer = slog.New(slog.NewTextHandler(w, opts))
+	tracing = true
+	return close, nil
+}
+
+// Tracing tells whether tracing is on, to skip computing what is only traced.
+func Tracing() bool {
+	return tracing
+}
+
+// Trace returns the logger of the current run, whose records carry its part
+// and input. It discards everything unless tracing is on.
+func Trace() *slog.Logger {
+	if current.trace == nil {
+		return tracer
+	}
+	return current.trace
+}
+
+func runTracer(part, file string) *slog.Logger {
+	return tracer.With("part", part, "input", strings.TrimPrefix(file, "input-"))
+}
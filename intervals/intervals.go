@@ -0,0 +1,238 @@
+// Package intervals stores sets of integers as sorted disjoint intervals, as
+// the fresh ingredient ID ranges of day 5.
+//
+// Intervals are kept closed ([Lo, Hi], both included) internally: the bounds
+// can then go up to the maximum value of the type, where a half-open end or
+// an `end+1` would overflow. Half-open intervals are converted on the way in.
+package intervals
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+)
+
+// Integer is the bound type of an interval.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Interval is the closed interval [Lo, Hi], empty when Lo > Hi.
+type Interval[T Integer] struct {
+	Lo, Hi T
+}
+
+// Closed returns [lo, hi].
+func Closed[T Integer](lo, hi T) Interval[T] {
+	return Interval[T]{Lo: lo, Hi: hi}
+}
+
+// HalfOpen returns [lo, hi), as the closed [lo, hi-1].
+func HalfOpen[T Integer](lo, hi T) Interval[T] {
+	if hi <= lo {
+		return empty[T]()
+	}
+	return Interval[T]{Lo: lo, Hi: hi - 1}
+}
+
+func empty[T Integer]() Interval[T] {
+	return Interval[T]{Lo: 1, Hi: 0}
+}
+
+func (iv Interval[T]) Empty() bool {
+	return iv.Lo > iv.Hi
+}
+
+func (iv Interval[T]) Contains(x T) bool {
+	return iv.Lo <= x && x <= iv.Hi
+}
+
+// Len returns the number of integers in the interval, which does not always
+// fit in T (the whole uint64 range has 2^64 of them).
+func (iv Interval[T]) Len() *big.Int {
+	if iv.Empty() {
+		return new(big.Int)
+	}
+	n := toBig(iv.Hi)
+	n.Sub(n, toBig(iv.Lo))
+	return n.Add(n, big.NewInt(1))
+}
+
+func (iv Interval[T]) String() string {
+	return fmt.Sprintf("[%d, %d]", iv.Lo, iv.Hi)
+}
+
+// touches tells whether a ends at or after the integer just before b.Lo, so
+// that they merge when a does not start after b. a.Hi+1 cannot overflow: it
+// is only computed when a.Hi is below b.Lo.
+func touches[T Integer](a, b Interval[T]) bool {
+	return a.Hi >= b.Lo || a.Hi+1 == b.Lo
+}
+
+func toBig[T Integer](x T) *big.Int {
+	if x < 0 {
+		return big.NewInt(int64(x))
+	}
+	return new(big.Int).SetUint64(uint64(x))
+}
+
+// IntervalSet is a set of integers stored as sorted, disjoint and
+// non-adjacent closed intervals. The zero value is the empty set.
+type IntervalSet[T Integer] struct {
+	intervals []Interval[T]
+}
+
+// New returns the set covering the given intervals, which can overlap.
+func New[T Integer](intervals ...Interval[T]) *IntervalSet[T] {
+	s := &IntervalSet[T]{}
+	sorted := slices.Clone(intervals)
+	slices.SortFunc(sorted, func(a, b Interval[T]) int {
+		switch {
+		case a.Lo < b.Lo:
+			return -1
+		case a.Lo > b.Lo:
+			return 1
+		}
+		return 0
+	})
+	for _, iv := range sorted {
+		s.appendMerged(iv)
+	}
+	return s
+}
+
+// appendMerged adds an interval starting at or after the last one.
+func (s *IntervalSet[T]) appendMerged(iv Interval[T]) {
+	if iv.Empty() {
+		return
+	}
+	if n := len(s.intervals); n > 0 && touches(s.intervals[n-1], iv) {
+		s.intervals[n-1].Hi = max(s.intervals[n-1].Hi, iv.Hi)
+		return
+	}
+	s.intervals = append(s.intervals, iv)
+}
+
+// Insert adds an interval, merging it with the ones it overlaps or touches.
+func (s *IntervalSet[T]) Insert(iv Interval[T]) {
+	if iv.Empty() {
+		return
+	}
+	// merge from the first interval ending at or after iv.Lo - 1 to the last
+	// one starting at or before iv.Hi + 1
+	from := sort.Search(len(s.intervals), func(i int) bool {
+		return touches(s.intervals[i], iv)
+	})
+	to := from
+	for to < len(s.intervals) && touches(iv, s.intervals[to]) {
+		iv.Lo = min(iv.Lo, s.intervals[to].Lo)
+		iv.Hi = max(iv.Hi, s.intervals[to].Hi)
+		to++
+	}
+	s.intervals = slices.Replace(s.intervals, from, to, iv)
+}
+
+// Find returns the interval containing x, by binary search.
+func (s *IntervalSet[T]) Find(x T) (Interval[T], bool) {
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return s.intervals[i].Hi >= x
+	})
+	if i < len(s.intervals) && s.intervals[i].Lo <= x {
+		return s.intervals[i], true
+	}
+	return Interval[T]{}, false
+}
+
+func (s *IntervalSet[T]) Contains(x T) bool {
+	_, ok := s.Find(x)
+	return ok
+}
+
+// Intervals returns the sorted disjoint intervals of the set, not to be
+// modified.
+func (s *IntervalSet[T]) Intervals() []Interval[T] {
+	return s.intervals
+}
+
+// Len returns the number of integers in the set.
+func (s *IntervalSet[T]) Len() *big.Int {
+	total := new(big.Int)
+	for _, iv := range s.intervals {
+		total.Add(total, iv.Len())
+	}
+	return total
+}
+
+func (s *IntervalSet[T]) Clone() *IntervalSet[T] {
+	return &IntervalSet[T]{intervals: slices.Clone(s.intervals)}
+}
+
+// Union returns the integers in s or o.
+func (s *IntervalSet[T]) Union(o *IntervalSet[T]) *IntervalSet[T] {
+	union := &IntervalSet[T]{}
+	i, j := 0, 0
+	for i < len(s.intervals) || j < len(o.intervals) {
+		if j == len(o.intervals) || i < len(s.intervals) && s.intervals[i].Lo <= o.intervals[j].Lo {
+			union.appendMerged(s.intervals[i])
+			i++
+		} else {
+			union.appendMerged(o.intervals[j])
+			j++
+		}
+	}
+	return union
+}
+
+// Intersection returns the integers in both s and o.
+func (s *IntervalSet[T]) Intersection(o *IntervalSet[T]) *IntervalSet[T] {
+	intersection := &IntervalSet[T]{}
+	i, j := 0, 0
+	for i < len(s.intervals) && j < len(o.intervals) {
+		a, b := s.intervals[i], o.intervals[j]
+		if overlap := (Interval[T]{Lo: max(a.Lo, b.Lo), Hi: min(a.Hi, b.Hi)}); !overlap.Empty() {
+			intersection.intervals = append(intersection.intervals, overlap)
+		}
+		if a.Hi < b.Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return intersection
+}
+
+// Difference returns the integers in s but not in o.
+func (s *IntervalSet[T]) Difference(o *IntervalSet[T]) *IntervalSet[T] {
+	difference := &IntervalSet[T]{}
+	j := 0
+	for _, iv := range s.intervals {
+		// skip the intervals of o ending before this one
+		for j < len(o.intervals) && o.intervals[j].Hi < iv.Lo {
+			j++
+		}
+		lo, covered := iv.Lo, false
+		for k := j; k < len(o.intervals) && o.intervals[k].Lo <= iv.Hi; k++ {
+			cut := o.intervals[k]
+			if cut.Lo > lo {
+				// cut.Lo-1 cannot underflow, it is above lo
+				difference.intervals = append(difference.intervals, Interval[T]{Lo: lo, Hi: cut.Lo - 1})
+			}
+			if cut.Hi >= iv.Hi {
+				covered = true
+				break
+			}
+			// cut.Hi+1 cannot overflow, it is below iv.Hi
+			lo = cut.Hi + 1
+		}
+		if !covered {
+			difference.intervals = append(difference.intervals, Interval[T]{Lo: lo, Hi: iv.Hi})
+		}
+	}
+	return difference
+}
+
+func (s *IntervalSet[T]) String() string {
+	return fmt.Sprint(s.intervals)
+}
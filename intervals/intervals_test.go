@@ -0,0 +1,174 @@
+package intervals
+
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestHalfOpen(t *testing.T) {
+	if iv := HalfOpen[uint64](0, math.MaxUint64); iv != Closed[uint64](0, math.MaxUint64-1) {
+		t.Errorf("HalfOpen(0, MaxUint64) = %s", iv)
+	}
+	if iv := HalfOpen[int64](math.MinInt64, math.MinInt64+1); iv != Closed[int64](math.MinInt64, math.MinInt64) {
+		t.Errorf("HalfOpen(MinInt64, MinInt64+1) = %s", iv)
+	}
+	// hi-1 would wrap around for these
+	for _, iv := range []Interval[int64]{
+		HalfOpen[int64](5, 5),
+		HalfOpen[int64](5, 3),
+		HalfOpen[int64](math.MinInt64, math.MinInt64),
+		HalfOpen[int64](math.MaxInt64, math.MinInt64),
+	} {
+		if !iv.Empty() || iv.Len().Sign() != 0 {
+			t.Errorf("%s is not empty", iv)
+		}
+	}
+	if iv := HalfOpen[uint64](0, 0); !iv.Empty() {
+		t.Errorf("HalfOpen(0, 0) = %s, not empty", iv)
+	}
+}
+
+func TestLen(t *testing.T) {
+	twoTo64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	if n := Closed[uint64](0, math.MaxUint64).Len(); n.Cmp(twoTo64) != 0 {
+		t.Errorf("Len of the uint64 range = %s, want %s", n, twoTo64)
+	}
+	if n := Closed[int64](math.MinInt64, math.MaxInt64).Len(); n.Cmp(twoTo64) != 0 {
+		t.Errorf("Len of the int64 range = %s, want %s", n, twoTo64)
+	}
+	if n := Closed[int64](-3, 3).Len(); n.Int64() != 7 {
+		t.Errorf("Len of [-3, 3] = %s", n)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name   string
+		insert []Interval[int]
+		want   []Interval[int]
+	}{
+		{"adjacent", []Interval[int]{{1, 3}, {4, 6}}, []Interval[int]{{1, 6}}},
+		{"adjacent before", []Interval[int]{{4, 6}, {1, 3}}, []Interval[int]{{1, 6}}},
+		{"gap", []Interval[int]{{1, 3}, {5, 6}}, []Interval[int]{{1, 3}, {5, 6}}},
+		{"bridge", []Interval[int]{{1, 3}, {7, 9}, {4, 6}}, []Interval[int]{{1, 9}}},
+		{"covering", []Interval[int]{{2, 3}, {5, 6}, {8, 9}, {1, 10}}, []Interval[int]{{1, 10}}},
+		{"inside", []Interval[int]{{1, 10}, {3, 4}}, []Interval[int]{{1, 10}}},
+		{"empty", []Interval[int]{{1, 3}, {6, 5}}, []Interval[int]{{1, 3}}},
+	}
+	for _, test := range tests {
+		s := &IntervalSet[int]{}
+		for _, iv := range test.insert {
+			s.Insert(iv)
+		}
+		if !slices.Equal(s.Intervals(), test.want) {
+			t.Errorf("%s: Insert %v = %v, want %v", test.name, test.insert, s, test.want)
+		}
+		if n := New(test.insert...); !slices.Equal(n.Intervals(), test.want) {
+			t.Errorf("%s: New(%v) = %v, want %v", test.name, test.insert, n, test.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	const top = math.MaxUint64
+	s := New(Closed[uint64](top-1, top), Closed[uint64](0, 0))
+	s.Insert(Closed[uint64](top, top))
+	s.Insert(Closed[uint64](1, top-2))
+	if want := []Interval[uint64]{{0, top}}; !slices.Equal(s.Intervals(), want) {
+		t.Errorf("Insert up to MaxUint64 = %v, want %v", s, want)
+	}
+	if !s.Contains(top) || !s.Contains(0) {
+		t.Errorf("%v does not contain its bounds", s)
+	}
+
+	edges := New(Closed[uint64](0, 0), Closed[uint64](top, top))
+	if got, want := s.Difference(edges).Intervals(), []Interval[uint64]{{1, top - 1}}; !slices.Equal(got, want) {
+		t.Errorf("Difference = %v, want %v", got, want)
+	}
+	if got := edges.Difference(s).Intervals(); len(got) != 0 {
+		t.Errorf("Difference = %v, want none", got)
+	}
+	if got, want := s.Intersection(edges).Intervals(), edges.Intervals(); !slices.Equal(got, want) {
+		t.Errorf("Intersection = %v, want %v", got, want)
+	}
+	if got, want := edges.Union(New(Closed[uint64](1, top-1))).Intervals(), s.Intervals(); !slices.Equal(got, want) {
+		t.Errorf("Union = %v, want %v", got, want)
+	}
+
+	low := New(Closed[int64](math.MinInt64, math.MinInt64))
+	low.Insert(Closed[int64](math.MinInt64+1, -1))
+	if want := []Interval[int64]{{math.MinInt64, -1}}; !slices.Equal(low.Intervals(), want) {
+		t.Errorf("Insert from MinInt64 = %v, want %v", low, want)
+	}
+	rest := New(Closed[int64](math.MinInt64, math.MaxInt64)).Difference(low)
+	if want := []Interval[int64]{{0, math.MaxInt64}}; !slices.Equal(rest.Intervals(), want) {
+		t.Errorf("Difference from MinInt64 = %v, want %v", rest, want)
+	}
+}
+
+// TestAlgebra checks the set operations against a membership table of every
+// value of int8 and uint8, bounds included.
+func TestAlgebra(t *testing.T) {
+	testAlgebra[int8](t, math.MinInt8, math.MaxInt8)
+	testAlgebra[uint8](t, 0, math.MaxUint8)
+}
+
+func testAlgebra[T Integer](t *testing.T, lo, hi T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	random := func() (*IntervalSet[T], map[T]bool) {
+		s, members := &IntervalSet[T]{}, map[T]bool{}
+		for range rng.IntN(6) {
+			// through int, hi-lo overflows int8
+			a := T(int(lo) + rng.IntN(int(hi)-int(lo)+1))
+			b := T(int(a) + rng.IntN(int(hi)-int(a)+1)/4)
+			if rng.IntN(4) == 0 {
+				// up to the bound
+				b = hi
+			}
+			s.Insert(Closed(a, b))
+			for x := a; ; x++ {
+				members[x] = true
+				if x == b {
+					break
+				}
+			}
+		}
+		return s, members
+	}
+	check := func(name string, s *IntervalSet[T], member func(T) bool) {
+		t.Helper()
+		count := int64(0)
+		for x := lo; ; x++ {
+			if member(x) {
+				count++
+			}
+			if s.Contains(x) != member(x) {
+				t.Fatalf("%s = %v: Contains(%d) = %v", name, s, x, s.Contains(x))
+			}
+			if x == hi {
+				break
+			}
+		}
+		if s.Len().Int64() != count {
+			t.Fatalf("%s = %v: Len = %s, want %d", name, s, s.Len(), count)
+		}
+		// sorted, disjoint and non-adjacent
+		for i, iv := range s.Intervals() {
+			if iv.Empty() || i > 0 && touches(s.Intervals()[i-1], iv) {
+				t.Fatalf("%s = %v: not normalized", name, s)
+			}
+		}
+	}
+	for range 500 {
+		a, inA := random()
+		b, inB := random()
+		check("a", a, func(x T) bool { return inA[x] })
+		check("a ∪ b", a.Union(b), func(x T) bool { return inA[x] || inB[x] })
+		check("a ∩ b", a.Intersection(b), func(x T) bool { return inA[x] && inB[x] })
+		check("a \\ b", a.Difference(b), func(x T) bool { return inA[x] && !inB[x] })
+		check("clone", a.Clone(), func(x T) bool { return inA[x] })
+	}
+}